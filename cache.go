@@ -0,0 +1,71 @@
+package konfetty
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedProvider wraps a Provider and caches the result of its last successful load for a fixed time-to-live. It is
+// safe for concurrent use.
+type CachedProvider[T any] struct {
+	provider   Provider[T]
+	ttl        time.Duration
+	serveStale bool
+	now        func() time.Time
+
+	mu       sync.Mutex
+	value    T
+	loaded   bool
+	loadedAt time.Time
+}
+
+// Cached wraps the provider in a CachedProvider. Loads within the ttl of the last successful load are served from the
+// cache, loads after it expired hit the wrapped provider again. Every load returns a deep copy of the cached value, so
+// processing it never mutates the cache.
+//
+//	provider := konfetty.Cached(remoteProvider, time.Minute)
+//	processor := konfetty.FromProvider(provider)
+func Cached[T any](provider Provider[T], ttl time.Duration) *CachedProvider[T] {
+	return &CachedProvider[T]{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// WithServeStale makes the provider serve the last successfully loaded value if reloading an expired value fails.
+func (c *CachedProvider[T]) WithServeStale() *CachedProvider[T] {
+	c.serveStale = true
+	return c
+}
+
+// Load implements Provider.
+func (c *CachedProvider[T]) Load() (T, error) {
+	return c.LoadContext(context.Background())
+}
+
+// LoadContext implements ContextProvider. The context is passed on to the wrapped provider.
+func (c *CachedProvider[T]) LoadContext(ctx context.Context) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded && c.now().Sub(c.loadedAt) < c.ttl {
+		return deepCopy(&c.value), nil
+	}
+
+	value, err := loadProviderOnce(ctx, c.provider)
+	if err != nil {
+		if c.loaded && c.serveStale {
+			return deepCopy(&c.value), nil
+		}
+
+		return value, err
+	}
+
+	c.value = value
+	c.loaded = true
+	c.loadedAt = c.now()
+
+	return deepCopy(&c.value), nil
+}
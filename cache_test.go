@@ -0,0 +1,122 @@
+//nolint:testpackage // We need access to the clock of the cached provider.
+package konfetty
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (c *countingProvider) Load() ([]string, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return []string{"value"}, nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func TestCached(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ServesFromCacheWithinTTL", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{now: time.Now()}
+		provider := &countingProvider{}
+		cached := Cached[[]string](provider, time.Minute)
+		cached.now = clock.Now
+
+		value, err := cached.Load()
+		must.NoError(t, err)
+		must.Eq(t, []string{"value"}, value)
+
+		// Mutating a loaded value must not affect the cache.
+		value[0] = "mutated"
+
+		clock.Advance(30 * time.Second)
+		value, err = cached.Load()
+		must.NoError(t, err)
+		must.Eq(t, []string{"value"}, value)
+		must.Eq(t, 1, provider.calls)
+
+		clock.Advance(30 * time.Second)
+		_, err = cached.Load()
+		must.NoError(t, err)
+		must.Eq(t, 2, provider.calls)
+	})
+
+	t.Run("ReloadFailure", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{now: time.Now()}
+		provider := &countingProvider{}
+		cached := Cached[[]string](provider, time.Minute)
+		cached.now = clock.Now
+
+		_, err := cached.Load()
+		must.NoError(t, err)
+
+		provider.err = errors.New("provider error")
+		clock.Advance(time.Hour)
+
+		_, err = cached.Load()
+		must.ErrorContains(t, err, "provider error")
+		must.Eq(t, 2, provider.calls)
+	})
+
+	t.Run("ServeStale", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{now: time.Now()}
+		provider := &countingProvider{}
+		cached := Cached[[]string](provider, time.Minute).WithServeStale()
+		cached.now = clock.Now
+
+		_, err := cached.Load()
+		must.NoError(t, err)
+
+		provider.err = errors.New("provider error")
+		clock.Advance(time.Hour)
+
+		value, err := cached.Load()
+		must.NoError(t, err)
+		must.Eq(t, []string{"value"}, value)
+		must.Eq(t, 2, provider.calls)
+
+		// Once the provider recovers, the fresh value replaces the stale one.
+		provider.err = nil
+		_, err = cached.Load()
+		must.NoError(t, err)
+		must.Eq(t, 3, provider.calls)
+	})
+
+	t.Run("NoStaleValueOnFirstFailure", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &countingProvider{err: errors.New("provider error")}
+		cached := Cached[[]string](provider, time.Minute).WithServeStale()
+
+		_, err := FromProvider[[]string](cached).Build()
+		must.ErrorContains(t, err, "provider error")
+	})
+}
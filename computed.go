@@ -0,0 +1,124 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// computedDefault is a default whose value is computed from the struct containing the target field.
+type computedDefault struct {
+	path     string
+	segments []pathSegment
+	compute  func(parent reflect.Value) reflect.Value
+}
+
+// WithComputedDefault fills the zero field at the given path relative to every Parent with the result of fn, which is
+// called with the Parent after its literal defaults were applied. Nil pointers to structs on the path are allocated.
+//
+//	konfetty.WithComputedDefault(processor, "Controller.Name", func(room *RoomConfig) string {
+//		return room.Name + " Controller"
+//	})
+func WithComputedDefault[T, Parent, Field any](p *Processor[T], field string, fn func(*Parent) Field) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	segments, err := parsePath(field)
+	if err != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("computed default: %w", err))
+		return p
+	}
+
+	if p.builder.computed == nil {
+		p.builder.computed = make(map[reflect.Type][]computedDefault)
+	}
+
+	parentType := reflect.TypeFor[Parent]()
+	p.builder.computed[parentType] = append(p.builder.computed[parentType], computedDefault{
+		path:     field,
+		segments: segments,
+		compute: func(parent reflect.Value) reflect.Value {
+			//nolint:errcheck,forcetypeassert // The parent is always of type Parent
+			return reflect.ValueOf(fn(parent.Addr().Interface().(*Parent)))
+		},
+	})
+
+	return p
+}
+
+// WithFieldDefault registers a default for the single field at the given path, relative to the root of the
+// data-structure. The value is assigned if the field is zero. Unlike defaults registered by type, this allows
+// defaulting fields by their location, e.g. fields of anonymous inline structs, whose type can't be named.
+//
+// Field defaults are applied like computed defaults of the root type: after the literal defaults registered for the
+// root, but before the defaults of nested types, which they take precedence over. Nil pointers to structs on the path
+// are allocated, so that the field can be set.
+//
+// This also allows defaulting nil interface fields, whose concrete type can't be inferred, by setting them to a value
+// of a concrete type. The defaults registered for that type are merged into it afterward, just like for interfaces
+// that were set when loading.
+//
+//	processor.WithFieldDefault("Server.Limits.MaxConns", 100)
+//	processor.WithFieldDefault("Plugin.Options", &PluginOptions{})
+func (p *Processor[T]) WithFieldDefault(field string, value any) *Processor[T] {
+	segments, err := parsePath(field)
+	if err != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("field default: %w", err))
+		return p
+	}
+
+	if p.builder.computed == nil {
+		p.builder.computed = make(map[reflect.Type][]computedDefault)
+	}
+
+	rootType := reflect.TypeFor[T]()
+	p.builder.computed[rootType] = append(p.builder.computed[rootType], computedDefault{
+		path:     field,
+		segments: segments,
+		compute: func(reflect.Value) reflect.Value {
+			// The value is copied, so that builds don't share its pointers, slices and maps.
+			return cloneValue(reflect.ValueOf(value), make(map[pointerKey]reflect.Value))
+		},
+	})
+
+	return p
+}
+
+func (d *defaulter) applyComputedDefaults(v reflect.Value, computed []computedDefault, path string) error {
+	for _, cd := range computed {
+		if cd.compute == nil {
+			continue
+		}
+
+		target, err := allocPath(v, cd.segments)
+		if err != nil {
+			return fmt.Errorf("computed default for %s: %w", cd.path, err)
+		}
+
+		if !target.CanSet() {
+			return fmt.Errorf("computed default for %s: %w: the field can't be set", cd.path, ErrUnknownPath)
+		}
+
+		if !d.isZero(target) {
+			continue
+		}
+
+		value := cd.compute(v)
+		if !value.IsValid() {
+			continue
+		}
+
+		before := reflect.New(target.Type()).Elem()
+		before.Set(target)
+
+		// String values are parsed into fields implementing encoding.TextUnmarshaler, just like string defaults.
+		if err = setField(target, value); err != nil {
+			return fmt.Errorf("computed default for %s: %w", cd.path, err)
+		}
+
+		d.origin = registeredDefault{index: -1}
+		d.record(joinPath(path, cd.path), before, target)
+	}
+
+	return nil
+}
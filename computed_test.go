@@ -0,0 +1,214 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type ComputedController struct {
+	Name     string
+	Location string
+}
+
+type ComputedRoom struct {
+	Name       string
+	Controller ComputedController
+}
+
+type ComputedHome struct {
+	Rooms []ComputedRoom
+}
+
+func TestWithComputedDefault(t *testing.T) {
+	t.Parallel()
+
+	config := &ComputedHome{
+		Rooms: []ComputedRoom{
+			{Name: "Kitchen"},
+			{Name: "Office", Controller: ComputedController{Name: "Custom"}},
+			{Name: "Garage"},
+		},
+	}
+
+	processor := konfetty.FromStruct(config)
+	processor = konfetty.WithComputedDefault(processor, "Controller.Name", func(room *ComputedRoom) string {
+		return room.Name + " Controller"
+	})
+	processor = konfetty.WithComputedDefault(processor, "Location", func(c *ComputedController) string {
+		return "near " + c.Name
+	})
+
+	result, err := processor.Build()
+	must.NoError(t, err)
+	must.Eq(t, []ComputedRoom{
+		{Name: "Kitchen", Controller: ComputedController{Name: "Kitchen Controller", Location: "near Kitchen Controller"}},
+		{Name: "Office", Controller: ComputedController{Name: "Custom", Location: "near Custom"}},
+		{Name: "Garage", Controller: ComputedController{Name: "Garage Controller", Location: "near Garage Controller"}},
+	}, result.Rooms)
+}
+
+func TestWithComputedDefaultAfterLiteralDefaults(t *testing.T) {
+	t.Parallel()
+
+	processor := konfetty.FromStruct(&ComputedRoom{}).
+		WithDefaults(ComputedRoom{Name: "Default", Controller: ComputedController{Location: "Hall"}}).
+		WithDefaults(ComputedController{Name: "Inner"})
+	processor = konfetty.WithComputedDefault(processor, "Controller.Name", func(room *ComputedRoom) string {
+		return room.Name + " Controller"
+	})
+	processor = konfetty.WithComputedDefault(processor, "Controller.Location", func(_ *ComputedRoom) string {
+		return "Computed"
+	})
+
+	result, err := processor.Build()
+	must.NoError(t, err)
+	must.Eq(t, &ComputedRoom{
+		Name:       "Default",
+		Controller: ComputedController{Name: "Default Controller", Location: "Hall"},
+	}, result)
+}
+
+func TestWithComputedDefaultErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.WithComputedDefault(konfetty.FromStruct(&ComputedRoom{}), "Controller..Name",
+			func(_ *ComputedRoom) string { return "" })
+
+		_, err := processor.Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidPath)
+	})
+
+	t.Run("UnknownPath", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.WithComputedDefault(konfetty.FromStruct(&ComputedRoom{}), "Controller.Unknown",
+			func(_ *ComputedRoom) string { return "" })
+
+		_, err := processor.Build()
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+	})
+
+	t.Run("TypeMismatch", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.WithComputedDefault(konfetty.FromStruct(&ComputedRoom{}), "Controller.Name",
+			func(_ *ComputedRoom) int { return 1 })
+
+		_, err := processor.Build()
+		must.ErrorContains(t, err, "not assignable")
+	})
+}
+
+func TestWithFieldDefault(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name   string
+		Nested struct {
+			Simple struct {
+				Name string
+				Age  int
+			}
+			Labels map[string]string
+		}
+		Rooms []ComputedRoom
+	}
+
+	t.Run("Locations", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{Rooms: []ComputedRoom{{}, {Name: "Office"}}}
+		config.Nested.Simple.Age = 42
+
+		result, err := konfetty.FromStruct(config).
+			WithDefaults(ComputedRoom{Name: "Room"}).
+			WithFieldDefault("Nested.Simple.Name", "X").
+			WithFieldDefault("Nested.Simple.Age", 18).
+			WithFieldDefault("Nested.Labels", map[string]string{"env": "dev"}).
+			WithFieldDefault("Rooms[0].Name", "Kitchen").
+			Build()
+		must.NoError(t, err)
+
+		must.Eq(t, "X", result.Nested.Simple.Name)
+		must.Eq(t, 42, result.Nested.Simple.Age)
+		must.Eq(t, map[string]string{"env": "dev"}, result.Nested.Labels)
+		must.Eq(t, []ComputedRoom{{Name: "Kitchen"}, {Name: "Office"}}, result.Rooms)
+	})
+
+	t.Run("NilInterface", func(t *testing.T) {
+		t.Parallel()
+
+		type Plugin struct {
+			Data   any
+			Shared any
+		}
+
+		// The concrete default fills the nil interface and receives the defaults of its own type afterward.
+		result, err := konfetty.FromStruct(&Plugin{}).
+			WithDefaults(ComputedController{Name: "Controller"}).
+			WithFieldDefault("Data", ComputedController{Location: "Hall"}).
+			WithFieldDefault("Shared", &ComputedController{}).
+			Build()
+		must.NoError(t, err)
+		must.Eq[any](t, ComputedController{Name: "Controller", Location: "Hall"}, result.Data)
+		must.Eq[any](t, &ComputedController{Name: "Controller"}, result.Shared)
+
+		// Interfaces that are already set are kept.
+		result, err = konfetty.FromStruct(&Plugin{Data: "set"}).
+			WithFieldDefault("Data", ComputedController{}).
+			Build()
+		must.NoError(t, err)
+		must.Eq[any](t, "set", result.Data)
+	})
+
+	t.Run("NilPointer", func(t *testing.T) {
+		t.Parallel()
+
+		type Limits struct {
+			MaxConns int
+			Timeout  int
+		}
+
+		type Server struct {
+			Limits **Limits
+		}
+
+		type Root struct {
+			Server *Server
+		}
+
+		// Nil pointers on the path are allocated.
+		result, err := konfetty.FromStruct(&Root{}).
+			WithFieldDefault("Server.Limits.MaxConns", 100).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, &Limits{MaxConns: 100}, *result.Server.Limits)
+
+		// Set values on the path are kept.
+		limits := &Limits{Timeout: 5}
+		result, err = konfetty.FromStruct(&Root{Server: &Server{Limits: &limits}}).
+			WithFieldDefault("Server.Limits.MaxConns", 100).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, &Limits{MaxConns: 100, Timeout: 5}, *result.Server.Limits)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).WithFieldDefault("Nested..Name", "X").Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidPath)
+
+		_, err = konfetty.FromStruct(&Config{}).WithFieldDefault("Nested.Simple.Age", "old").Build()
+		must.ErrorContains(t, err, "not assignable")
+
+		_, err = konfetty.FromStruct(&Config{}).WithFieldDefault("Nested.Missing", "X").Build()
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+	})
+}
@@ -0,0 +1,98 @@
+package konfetty
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// concreteType maps generic map values with a discriminator field of the given value to a concrete type.
+type concreteType struct {
+	field string
+	value string
+	typ   reflect.Type
+}
+
+// WithConcreteType registers a concrete type for polymorphic values. Values stored in interfaces, e.g. the elements of
+// a `[]any` slice, are often loaded as generic `map[string]any` maps, e.g. by encoding/json, so no default matches
+// them. At the start of the defaults stage, every such map whose discriminator field holds the given value is
+// converted into a value of type t, which then receives the defaults of its type.
+//
+// The type must be a struct or a pointer to a struct and has to fit into the interface holding the map. The map is
+// converted via encoding/json, so json struct tags are respected. Conversion errors fail the build.
+//
+//	processor.
+//		WithConcreteType("type", "light", reflect.TypeFor[LightDevice]()).
+//		WithConcreteType("type", "thermostat", reflect.TypeFor[*ThermostatDevice]())
+func (p *Processor[T]) WithConcreteType(discriminatorField, value string, t reflect.Type) *Processor[T] {
+	if t == nil || (t.Kind() != reflect.Struct && (t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct)) {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("concrete type for %s=%s: %v is not a struct or a pointer to "+
+			"a struct", discriminatorField, value, t))
+		return p
+	}
+
+	p.builder.concretes = append(p.builder.concretes, concreteType{field: discriminatorField, value: value, typ: t})
+	return p
+}
+
+// convertConcreteTypes replaces the generic maps stored in the interfaces of cfg with values of their registered
+// concrete types.
+func (b *Builder[T]) convertConcreteTypes(cfg *T) error {
+	if len(b.concretes) == 0 {
+		return nil
+	}
+
+	return traverse(reflect.ValueOf(cfg).Elem(), b.tags(), func(v reflect.Value, path string) error {
+		if v.Kind() != reflect.Interface || v.IsNil() || !v.CanSet() {
+			return nil
+		}
+
+		m, ok := v.Elem().Interface().(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		ct, ok := b.concreteType(m, v.Type())
+		if !ok {
+			return nil
+		}
+
+		concrete, err := convertMap(m, ct.typ)
+		if err != nil {
+			return wrapPath(path, fmt.Errorf("convert %s=%s to %s: %w", ct.field, ct.value, ct.typ, err))
+		}
+		v.Set(concrete)
+
+		return nil
+	})
+}
+
+// concreteType returns the concrete type registered for the map, if it fits into interfaces of type iface.
+func (b *Builder[T]) concreteType(m map[string]any, iface reflect.Type) (concreteType, bool) {
+	for _, ct := range b.concretes {
+		if value, ok := m[ct.field].(string); ok && value == ct.value && ct.typ.Implements(iface) {
+			return ct, true
+		}
+	}
+
+	return concreteType{}, false
+}
+
+// convertMap converts the generic map into a value of type t by round-tripping it through JSON.
+func convertMap(m map[string]any, t reflect.Type) (reflect.Value, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	target := reflect.New(derefType(t))
+	if err = json.Unmarshal(data, target.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return target, nil
+	}
+
+	return target.Elem(), nil
+}
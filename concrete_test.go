@@ -0,0 +1,76 @@
+package konfetty_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type ConcreteLight struct {
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Brightness int    `json:"brightness"`
+}
+
+type ConcreteThermostat struct {
+	Type   string  `json:"type"`
+	Target float64 `json:"target"`
+}
+
+func TestWithConcreteType(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Devices []any          `json:"devices"`
+		Primary any            `json:"primary"`
+		Named   map[string]any `json:"named"`
+	}
+
+	data := []byte(`{
+		"devices": [
+			{"type": "light", "name": "lamp"},
+			{"type": "thermostat"},
+			{"type": "unknown", "name": "other"}
+		],
+		"primary": {"type": "light", "brightness": 10},
+		"named": {"hall": {"type": "light"}}
+	}`)
+
+	cfg, err := konfetty.FromBytes[Config](data, json.Unmarshal).
+		WithConcreteType("type", "light", reflect.TypeFor[ConcreteLight]()).
+		WithConcreteType("type", "thermostat", reflect.TypeFor[*ConcreteThermostat]()).
+		WithDefaults(ConcreteLight{Name: "light", Brightness: 50}, ConcreteThermostat{Target: 21.5}).
+		Build()
+	must.NoError(t, err)
+
+	must.Eq(t, []any{
+		ConcreteLight{Type: "light", Name: "lamp", Brightness: 50},
+		&ConcreteThermostat{Type: "thermostat", Target: 21.5},
+		map[string]any{"type": "unknown", "name": "other"},
+	}, cfg.Devices)
+	must.Eq[any](t, ConcreteLight{Type: "light", Name: "light", Brightness: 10}, cfg.Primary)
+	must.Eq[any](t, ConcreteLight{Type: "light", Name: "light", Brightness: 50}, cfg.Named["hall"])
+
+	t.Run("ConversionError", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := &Config{Devices: []any{map[string]any{"type": "light", "brightness": "bright"}}}
+		_, err := konfetty.FromStruct(cfg).
+			WithConcreteType("type", "light", reflect.TypeFor[ConcreteLight]()).
+			Build()
+		must.ErrorContains(t, err, "Devices[0]: convert type=light to konfetty_test.ConcreteLight")
+	})
+
+	t.Run("InvalidType", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithConcreteType("type", "light", reflect.TypeFor[string]()).
+			Build()
+		must.ErrorContains(t, err, "is not a struct or a pointer to a struct")
+	})
+}
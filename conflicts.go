@@ -0,0 +1,133 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// defaultRef is a registered default along with the type and position it was registered with, for error messages.
+type defaultRef struct {
+	value reflect.Value
+	typ   reflect.Type
+	index int
+}
+
+func (r defaultRef) String() string {
+	return fmt.Sprintf("default #%d of type %s", r.index, r.typ)
+}
+
+// conflictFinder looks for fields that more than one registered default provides a different value for.
+type conflictFinder struct {
+	// defaults holds the registered defaults grouped by their struct type, regardless of whether they were registered
+	// as values or pointers.
+	defaults map[reflect.Type][]defaultRef
+	tags     tagResolver
+	errs     []error
+}
+
+// findConflicts analyzes the registered defaults without applying them and returns an ErrConflictingDefaults error
+// for every field with competing values. Two defaults compete if they provide different non-zero values for the
+// same field, either because both are registered for the same type or because one of them is nested in the default
+// of an outer type, e.g. a struct embedding the other default's type. Fields merged with `merge=add` never conflict.
+func findConflicts(defaults map[reflect.Type][]any, tags tagResolver) error {
+	f := &conflictFinder{
+		defaults: make(map[reflect.Type][]defaultRef),
+		tags:     tags,
+	}
+
+	types := make([]reflect.Type, 0, len(defaults))
+	for t := range defaults {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	for _, t := range types {
+		for i, dv := range defaults[t] {
+			v := dereference(reflect.ValueOf(dv))
+			if v.Kind() != reflect.Struct {
+				continue
+			}
+
+			f.defaults[v.Type()] = append(f.defaults[v.Type()], defaultRef{value: v, typ: t, index: i})
+		}
+	}
+
+	structTypes := make([]reflect.Type, 0, len(f.defaults))
+	for t := range f.defaults {
+		structTypes = append(structTypes, t)
+	}
+	sort.Slice(structTypes, func(i, j int) bool { return structTypes[i].String() < structTypes[j].String() })
+
+	for _, structType := range structTypes {
+		refs := f.defaults[structType]
+		for i, a := range refs {
+			for _, b := range refs[i+1:] {
+				f.compare(a.value, b.value, structType.Name(), a, b)
+			}
+
+			f.findNested(a.value, structType.Name(), a)
+		}
+	}
+
+	return errors.Join(f.errs...)
+}
+
+// findNested compares the nested structs of the default against the defaults registered for their types.
+func (f *conflictFinder) findNested(v reflect.Value, path string, outer defaultRef) {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() || f.tags.skips(field) {
+			continue
+		}
+
+		fv := dereference(v.Field(i))
+		if fv.Kind() != reflect.Struct || fv.IsZero() {
+			continue
+		}
+
+		fieldPath := joinPath(path, f.tags.fieldName(field))
+		for _, inner := range f.defaults[fv.Type()] {
+			f.compare(fv, inner.value, fieldPath, outer, inner)
+		}
+
+		f.findNested(fv, fieldPath, outer)
+	}
+}
+
+// compare records a conflict for every field that both a and b provide a different value for.
+func (f *conflictFinder) compare(a, b reflect.Value, path string, refA, refB defaultRef) {
+	a, b = dereference(a), dereference(b)
+	if !a.IsValid() || !b.IsValid() || a.IsZero() || b.IsZero() {
+		return
+	}
+
+	//nolint:exhaustive // Structs and maps are compared per field and key; other kinds are compared as a whole
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := range a.NumField() {
+			field := a.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if opts, err := f.tags.parse(field); err == nil && (opts.merge == mergeAdd || opts.skip || opts.noDefault) {
+				continue
+			}
+
+			f.compare(a.Field(i), b.Field(i), joinPath(path, f.tags.fieldName(field)), refA, refB)
+		}
+	case reflect.Map:
+		for _, key := range sortedKeys(a) {
+			if bv := b.MapIndex(key); bv.IsValid() {
+				f.compare(a.MapIndex(key), bv, keyPath(path, key), refA, refB)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			f.errs = append(f.errs, fmt.Errorf("%w: %s: %v (%s) and %v (%s)",
+				ErrConflictingDefaults, path, a, refA, b, refB))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithConflictingDefaultsError(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		Enabled bool
+		Level   int
+		Retries int `konfetty:"merge=add"`
+	}
+
+	type Light struct {
+		Base
+		Brightness int
+		Labels     map[string]string
+	}
+
+	tests := []struct {
+		name     string
+		defaults []any
+		expected []string
+	}{
+		{
+			name: "Compatible",
+			defaults: []any{
+				Base{Enabled: true, Retries: 1},
+				Base{Level: 2, Retries: 2},
+				Light{Base: Base{Enabled: true}, Brightness: 50, Labels: map[string]string{"a": "b"}},
+				&Light{Labels: map[string]string{"a": "b", "c": "d"}},
+			},
+		},
+		{
+			name: "SameType",
+			defaults: []any{
+				Light{Brightness: 50},
+				&Light{Brightness: 75},
+			},
+			expected: []string{"Light.Brightness: 75 (default #0 of type *konfetty_test.Light) and 50"},
+		},
+		{
+			name: "Embedded",
+			defaults: []any{
+				Base{Level: 1},
+				Light{Base: Base{Level: 3}},
+			},
+			expected: []string{"Light.Base.Level: 3 (default #0 of type konfetty_test.Light) and 1"},
+		},
+		{
+			name: "MapKeys",
+			defaults: []any{
+				Light{Labels: map[string]string{"room": "kitchen"}},
+				Light{Labels: map[string]string{"room": "office"}},
+			},
+			expected: []string{"Light.Labels[room]: kitchen"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := konfetty.FromStruct(&Light{}).
+				WithDefaults(tt.defaults...).
+				WithConflictingDefaultsError().
+				Build()
+
+			if len(tt.expected) == 0 {
+				must.NoError(t, err)
+				return
+			}
+
+			must.ErrorIs(t, err, konfetty.ErrConflictingDefaults)
+			for _, expected := range tt.expected {
+				must.ErrorContains(t, err, expected)
+			}
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Light{}).
+			WithDefaults(Light{Brightness: 50}, Light{Brightness: 75}).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, 75, result.Brightness)
+	})
+}
@@ -0,0 +1,117 @@
+package konfetty
+
+import (
+	"reflect"
+)
+
+// deepCopy returns a copy of src that shares no pointers, slices, maps or interfaces with the original. Unexported
+// struct fields can't be set via reflection and are therefore copied shallowly.
+func deepCopy[T any](src *T) T {
+	v := reflect.ValueOf(src).Elem()
+
+	//nolint:errcheck,forcetypeassert // The clone of a T is always a T
+	return cloneValue(v, make(map[pointerKey]reflect.Value)).Interface().(T)
+}
+
+// pointerKey identifies a cloned pointer. The type is part of the key, as a struct and its first field share their
+// address, so a pointer to the struct and a pointer to the field must not share a clone.
+type pointerKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func cloneValue(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	//nolint:exhaustive // Only reference and composite kinds need to be cloned; other kinds are copied by value
+	switch v.Kind() {
+	case reflect.Ptr:
+		return clonePointer(v, seen)
+	case reflect.Struct:
+		return cloneStruct(v, seen)
+	case reflect.Slice:
+		return cloneSlice(v, seen)
+	case reflect.Array:
+		return cloneArray(v, seen)
+	case reflect.Map:
+		return cloneMap(v, seen)
+	case reflect.Interface:
+		return cloneInterface(v, seen)
+	default:
+		return v
+	}
+}
+
+func clonePointer(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type())
+	}
+
+	key := pointerKey{ptr: v.Pointer(), typ: v.Type()}
+	if clone, ok := seen[key]; ok {
+		return clone
+	}
+
+	clone := reflect.New(v.Type().Elem())
+	seen[key] = clone
+	clone.Elem().Set(cloneValue(v.Elem(), seen))
+
+	return clone
+}
+
+func cloneStruct(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	clone := reflect.New(v.Type()).Elem()
+	clone.Set(v)
+
+	for i := range v.NumField() {
+		if v.Type().Field(i).IsExported() {
+			clone.Field(i).Set(cloneValue(v.Field(i), seen))
+		}
+	}
+
+	return clone
+}
+
+func cloneSlice(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type())
+	}
+
+	clone := reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+	for i := range v.Len() {
+		clone.Index(i).Set(cloneValue(v.Index(i), seen))
+	}
+
+	return clone
+}
+
+func cloneArray(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	clone := reflect.New(v.Type()).Elem()
+	for i := range v.Len() {
+		clone.Index(i).Set(cloneValue(v.Index(i), seen))
+	}
+
+	return clone
+}
+
+func cloneMap(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type())
+	}
+
+	clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+	for _, key := range v.MapKeys() {
+		clone.SetMapIndex(key, cloneValue(v.MapIndex(key), seen))
+	}
+
+	return clone
+}
+
+func cloneInterface(v reflect.Value, seen map[pointerKey]reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type())
+	}
+
+	clone := reflect.New(v.Type()).Elem()
+	clone.Set(cloneValue(v.Elem(), seen))
+
+	return clone
+}
@@ -0,0 +1,96 @@
+//nolint:testpackage // We want to thoroughly test the underlying copy logic.
+package konfetty
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name     string
+		Next     *Node
+		Children []*Node
+		Values   map[string][]int
+		Data     any
+		Fixed    [2]*Node
+		private  *Node
+	}
+
+	shared := &Node{Name: "shared"}
+	original := &Node{
+		Name:     "root",
+		Children: []*Node{shared, shared},
+		Values:   map[string][]int{"a": {1, 2}},
+		Data:     &Node{Name: "data"},
+		Fixed:    [2]*Node{shared, nil},
+		private:  shared,
+	}
+	original.Next = original
+
+	clone := deepCopy(original)
+
+	must.Eq(t, "root", clone.Name)
+	must.True(t, clone.Next == clone.Next.Next)
+	must.False(t, clone.Next == original)
+
+	// Shared pointers stay shared within the copy, but not with the original.
+	must.True(t, clone.Children[0] == clone.Children[1])
+	must.True(t, clone.Children[0] == clone.Fixed[0])
+	must.False(t, clone.Children[0] == shared)
+	must.Nil(t, clone.Fixed[1])
+
+	clone.Values["a"][0] = 42
+	must.Eq(t, 1, original.Values["a"][0])
+
+	data, ok := clone.Data.(*Node)
+	must.True(t, ok)
+	must.Eq(t, "data", data.Name)
+	must.False(t, data == original.Data)
+
+	// Unexported fields are copied shallowly.
+	must.True(t, clone.private == shared)
+}
+
+func TestDeepCopyNil(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Items  []string
+		Labels map[string]string
+		Ptr    *int
+		Data   any
+	}
+
+	clone := deepCopy(&Config{})
+	must.Nil(t, clone.Items)
+	must.Nil(t, clone.Labels)
+	must.Nil(t, clone.Ptr)
+	must.Nil(t, clone.Data)
+}
+
+func TestDeepCopyInteriorPointers(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		X int
+		Y string
+	}
+
+	type Config struct {
+		A *Inner
+		B *int
+	}
+
+	// A struct and its first field share their address, so the pointers have to be told apart by their type.
+	inner := &Inner{X: 1, Y: "inner"}
+	clone := deepCopy(&Config{A: inner, B: &inner.X})
+
+	must.Eq(t, Inner{X: 1, Y: "inner"}, *clone.A)
+	must.Eq(t, 1, *clone.B)
+	must.False(t, clone.A == inner)
+	must.False(t, clone.B == &inner.X)
+}
@@ -0,0 +1,76 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithSliceDedupe adds a transformer removing duplicate elements from the slices at the given paths, keeping the first
+// occurrence of every element and the order of the elements. The elements must be comparable; slices of other elements,
+// e.g. of slices or maps, make the build fail. Like other transformers, it runs in the order it was added.
+//
+//	processor.WithSliceDedupe("Toppings", "Schedule.Days")
+func (p *Processor[T]) WithSliceDedupe(paths ...string) *Processor[T] {
+	segments := make([][]pathSegment, 0, len(paths))
+	for _, path := range paths {
+		s, err := parsePath(path)
+		if err != nil {
+			p.builder.errs = append(p.builder.errs, fmt.Errorf("slice dedupe: %w", err))
+			return p
+		}
+		segments = append(segments, s)
+	}
+
+	return p.WithTransformerE(func(cfg *T) error {
+		root := reflect.ValueOf(cfg).Elem()
+		for i, s := range segments {
+			if err := dedupeSlice(root, s); err != nil {
+				return fmt.Errorf("slice dedupe for %s: %w", paths[i], err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// dedupeSlice removes duplicate elements from the slice at the path relative to root, in place.
+func dedupeSlice(root reflect.Value, segments []pathSegment) error {
+	v, err := followPath(root, segments)
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: the value is of type %s, not a slice", ErrUnknownPath, v.Type())
+	}
+
+	if !v.CanSet() {
+		return fmt.Errorf("%w: the slice can't be set", ErrUnknownPath)
+	}
+
+	seen := make(map[any]bool, v.Len())
+	n := 0
+	for i := range v.Len() {
+		elem := v.Index(i)
+		if !elem.Comparable() {
+			return fmt.Errorf("element %d of type %s isn't comparable", i, elem.Type())
+		}
+
+		key := elem.Interface()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		v.Index(n).Set(elem)
+		n++
+	}
+
+	// Clear the dropped elements, so that they don't keep values alive through the backing array.
+	for i := n; i < v.Len(); i++ {
+		v.Index(i).SetZero()
+	}
+	v.SetLen(n)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithSliceDedupe(t *testing.T) {
+	t.Parallel()
+
+	type Schedule struct {
+		Days []int
+	}
+
+	type Config struct {
+		Toppings []string
+		Schedule *Schedule
+		Options  [][]string
+	}
+
+	t.Run("Dedupe", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := konfetty.FromStruct(&Config{
+			Toppings: []string{"cheese", "ham", "cheese", "olives", "ham"},
+			Schedule: &Schedule{Days: []int{5, 1, 5, 5, 2, 1}},
+		}).
+			WithSliceDedupe("Toppings", "Schedule.Days").
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, []string{"cheese", "ham", "olives"}, cfg.Toppings)
+		must.Eq(t, []int{5, 1, 2}, cfg.Schedule.Days)
+	})
+
+	t.Run("NilSlice", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := konfetty.FromStruct(&Config{Schedule: &Schedule{}}).
+			WithSliceDedupe("Toppings", "Schedule.Days").
+			Build()
+
+		must.NoError(t, err)
+		must.Nil(t, cfg.Toppings)
+	})
+
+	t.Run("InvalidPaths", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).WithSliceDedupe("Schedule.Days").Build()
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+
+		_, err = konfetty.FromStruct(&Config{Schedule: &Schedule{}}).WithSliceDedupe("Schedule").Build()
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+	})
+
+	t.Run("NotComparable", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{Options: [][]string{{"a"}, {"a"}}}).
+			WithSliceDedupe("Options").
+			Build()
+		must.ErrorContains(t, err, "isn't comparable")
+	})
+}
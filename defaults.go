@@ -1,11 +1,109 @@
 package konfetty
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/nikoksr/konfetty/internal/convert"
 )
 
+// defaulter holds the configuration and state of a single defaulting pass.
+type defaulter struct {
+	defaults map[reflect.Type][]any
+	computed map[reflect.Type][]computedDefault
+	tags     tagResolver
+
+	// catchAll supplies defaults for struct types without registered defaults.
+	catchAll func(reflect.Type) (any, bool)
+
+	// interfaceDefaults apply to every struct implementing their interface, see WithInterfaceDefault.
+	interfaceDefaults []interfaceDefault
+
+	// report receives the changes made by the defaulting pass, if set.
+	report *Report
+
+	// origin is the default currently being merged, which is recorded in the report as the source of changes.
+	origin registeredDefault
+
+	// scope is the path of the scoped defaults applied by the defaulter, see WithScopedDefaults. It's empty for the
+	// defaults added with WithDefaults.
+	scope string
+
+	// copyPointers makes the defaulter work on copies of pointer values stored in maps instead of defaulting the
+	// shared pointee in place.
+	copyPointers bool
+
+	// tagDefaults enables reading field defaults from `default` struct tags.
+	tagDefaults bool
+
+	// emptyAsUnset makes empty slices and maps count as unset, see WithEmptyAsUnset.
+	emptyAsUnset bool
+
+	// foldMapKeys makes map defaults compare string keys case-insensitively, see WithKeyCaseInsensitiveDefaults.
+	foldMapKeys bool
+
+	// overwrite makes defaults replace set values instead of only filling unset ones, see ResetToDefaults. written
+	// holds the paths overwritten so far, which count as set, so that the defaults take the same precedence as when
+	// filling unset values.
+	overwrite bool
+	written   map[string]bool
+
+	// maxDepth limits the nesting depth the defaults are applied to, unless it's zero, see WithMaxDepth. depth is the
+	// depth of the value currently being defaulted.
+	maxDepth int
+	depth    int
+
+	// zeroFuncs decide whether values of their type are unset and get filled by defaults, instead of
+	// reflect.Value.IsZero.
+	zeroFuncs map[reflect.Type]func(reflect.Value) bool
+
+	// visited holds the pointers on the current traversal path and is used to detect circular references.
+	visited map[uintptr]bool
+}
+
+// registeredDefault is a default value along with its position among the defaults registered for its type. Defaults
+// that weren't registered, e.g. the ones supplied by the catch-all, have an index of -1. Defaults read from struct tags
+// have no value, but the tag they were parsed from, and values supplied by a FieldDefaulter the type implementing it.
+type registeredDefault struct {
+	value     any
+	index     int
+	tag       string
+	defaulter reflect.Type
+}
+
+// ApplyDefaults applies the given defaults to cfg, without loading, transforming or validating it. The defaults are
+// keyed by their type and take precedence like the ones added with WithDefaults; global defaults aren't applied.
+// Unlike Build, which may work on a copy, it mutates cfg in place. This is useful in tests and small scripts.
+//
+//	cfg := &MyConfig{Name: "app"}
+//	err := konfetty.ApplyDefaults(cfg, DatabaseConfig{Port: 5432})
+func ApplyDefaults[T any](cfg *T, defaults ...any) error {
+	if cfg == nil {
+		return ErrNilConfig
+	}
+
+	byType := make(map[reflect.Type][]any, len(defaults))
+	for _, dv := range defaults {
+		t := reflect.TypeOf(dv)
+		if err := checkDefaultType(t); err != nil {
+			return err
+		}
+
+		byType[t] = append(byType[t], dv)
+	}
+
+	return applyDefaults(cfg, byType)
+}
+
 // applyDefaults is the entry point for applying default values to the loaded config.
 func applyDefaults(config any, defaults map[reflect.Type][]any) error {
+	d := &defaulter{defaults: defaults}
+
+	return d.apply(config)
+}
+
+func (d *defaulter) apply(config any) error {
 	v := reflect.ValueOf(config)
 
 	if v.Kind() != reflect.Ptr {
@@ -16,35 +114,77 @@ func applyDefaults(config any, defaults map[reflect.Type][]any) error {
 		return ErrNilConfig
 	}
 
-	visited := make(map[uintptr]bool)
+	d.visited = make(map[uintptr]bool)
+	d.depth = 0
 
-	return applyDefaultsRecursive(v.Elem(), defaults, visited)
+	return d.applyDefaultsRecursive(v.Elem(), "")
 }
 
-// applyDefaultsRecursive contains the core logic for applying default values to the config.
-func applyDefaultsRecursive(v reflect.Value, defaults map[reflect.Type][]any, visited map[uintptr]bool) error {
-	if err := checkCircularReference(v, visited); err != nil {
-		return err
+// applyDefaultsRecursive contains the core logic for applying default values to the config. The path of v relative to
+// the root of the config is used to point out where errors occurred.
+func (d *defaulter) applyDefaultsRecursive(v reflect.Value, path string) error {
+	// Scalars don't count as a level, so that skipping the fields of flat structs doesn't change the depth reached.
+	if d.maxDepth > 0 && !isScalar(v.Kind()) {
+		if d.depth >= d.maxDepth {
+			return wrapPath(path, fmt.Errorf("%w: the limit is %d", ErrMaxDepthExceeded, d.maxDepth))
+		}
+
+		d.depth++
+		defer func() { d.depth-- }()
+	}
+
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		if err := checkCircularReference(v, d.visited); err != nil {
+			return wrapPath(path, err)
+		}
+		defer delete(d.visited, v.Pointer())
 	}
 
 	t := v.Type()
 
-	if err := applyTypeDefaults(v, defaults[t]); err != nil {
+	typeDefaults, err := d.resolveTypeDefaults(t)
+	if err != nil {
+		return wrapPath(path, err)
+	}
+
+	if err = d.applyTypeDefaults(v, typeDefaults, path); err != nil {
 		return err
 	}
 
+	if t.Kind() == reflect.Struct && len(d.interfaceDefaults) > 0 {
+		if err = d.applyInterfaceDefaults(v, path); err != nil {
+			return err
+		}
+	}
+
+	if computed := d.computed[t]; len(computed) > 0 && v.CanAddr() {
+		if err = d.applyComputedDefaults(v, computed, path); err != nil {
+			return wrapPath(path, err)
+		}
+	}
+
 	//nolint:exhaustive // Only handling relevant types for config structures; other types don't need special processing
 	switch t.Kind() {
 	case reflect.Struct:
-		return handleStruct(v, defaults, visited)
+		return d.handleStruct(v, path)
 	case reflect.Slice:
-		return handleSlice(v, defaults, visited)
+		if isBytes(t) {
+			// Byte slices, e.g. json.RawMessage, are opaque values that are only defaulted as a whole.
+			return nil
+		}
+
+		return d.handleSlice(v, path)
+	case reflect.Array:
+		// Arrays are values, so their elements can only be defaulted in place if the array itself is addressable.
+		if v.CanAddr() {
+			return d.handleSlice(v, path)
+		}
 	case reflect.Map:
-		return handleMap(v, defaults, visited)
+		return d.handleMap(v, path)
 	case reflect.Ptr:
-		return handlePointer(v, defaults, visited)
+		return d.handlePointer(v, path)
 	case reflect.Interface:
-		return handleInterface(v, defaults, visited)
+		return d.handleInterface(v, path)
 	default:
 		// Other kinds don't need special handling
 	}
@@ -64,9 +204,66 @@ func checkCircularReference(v reflect.Value, visited map[uintptr]bool) error {
 	return nil
 }
 
-func applyTypeDefaults(v reflect.Value, typeDefaults []any) error {
+// wrapPath prefixes err with the path of the value it occurred at. Errors at the root of the config are returned as-is.
+func wrapPath(path string, err error) error {
+	if err == nil || path == "" {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// typeDefaults returns the defaults for values of type t. Defaults registered for a struct type T and for *T both
+// apply to values of type T, regardless of whether they are reached through a pointer or not. Pointers to structs get
+// no defaults of their own, so that every default is applied exactly once via the pointee. Defaults registered as
+// values take precedence over defaults registered as pointers.
+func (d *defaulter) typeDefaults(t reflect.Type) []registeredDefault {
+	var typeDefaults []registeredDefault
+
+	switch {
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+		return nil
+	case t.Kind() == reflect.Struct:
+		typeDefaults = appendRegistered(typeDefaults, d.defaults[reflect.PointerTo(t)])
+	default:
+	}
+
+	return appendRegistered(typeDefaults, d.defaults[t])
+}
+
+func appendRegistered(dst []registeredDefault, values []any) []registeredDefault {
+	for i, value := range values {
+		dst = append(dst, registeredDefault{value: value, index: i})
+	}
+
+	return dst
+}
+
+// resolveTypeDefaults returns the defaults for values of type t, consulting the catch-all for struct types without
+// registered defaults.
+func (d *defaulter) resolveTypeDefaults(t reflect.Type) ([]registeredDefault, error) {
+	typeDefaults := d.typeDefaults(t)
+	if len(typeDefaults) > 0 || d.catchAll == nil || t.Kind() != reflect.Struct {
+		return typeDefaults, nil
+	}
+
+	dv, ok := d.catchAll(t)
+	if !ok || dv == nil {
+		return nil, nil
+	}
+
+	if dt := derefType(reflect.TypeOf(dv)); dt != t {
+		return nil, fmt.Errorf("%w: catch-all default of type %s doesn't match type %s", ErrInvalidDefault, dt, t)
+	}
+
+	return []registeredDefault{{value: dv, index: -1}}, nil
+}
+
+func (d *defaulter) applyTypeDefaults(v reflect.Value, typeDefaults []registeredDefault, path string) error {
 	for i := len(typeDefaults) - 1; i >= 0; i-- {
-		if err := mergeDefault(v, reflect.ValueOf(typeDefaults[i])); err != nil {
+		d.origin = typeDefaults[i]
+		d.match()
+		if err := d.mergeDefault(v, reflect.ValueOf(typeDefaults[i].value), path); err != nil {
 			return err
 		}
 	}
@@ -74,26 +271,106 @@ func applyTypeDefaults(v reflect.Value, typeDefaults []any) error {
 	return nil
 }
 
-func handleStruct(v reflect.Value, defaults map[reflect.Type][]any, visited map[uintptr]bool) error {
-	for i := range v.NumField() {
-		if err := applyDefaultsRecursive(v.Field(i), defaults, visited); err != nil {
+func (d *defaulter) handleStruct(v reflect.Value, path string) error {
+	fd := fieldDefaulter(v)
+	fields := d.tags.fields(v.Type())
+	if fd == nil && d.skipFields(v.Type(), fields) {
+		// The defaults of flat structs are already merged by the struct's own defaults, so there is nothing left to
+		// recurse into.
+		return nil
+	}
+
+	for i, field := range fields {
+		fieldPath := joinPath(path, d.tags.fieldName(field.StructField))
+		if field.err != nil {
+			return wrapPath(fieldPath, field.err)
+		}
+
+		if field.opts.weakRef || field.opts.skip {
+			continue
+		}
+
+		// Nothing reached through unexported fields can be set, except for the fields promoted from embedded structs.
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		if d.report != nil {
+			d.report.Stats.FieldsVisited++
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Ptr && fv.IsNil() && fv.CanSet() && d.hasDefaults(field.Type.Elem()) {
+			// Nil embedded pointers are allocated, so that the defaults of the embedded type can be merged.
+			fv.Set(reflect.New(field.Type.Elem()))
+		}
+
+		if fd != nil && !field.opts.noDefault {
+			if err := d.applyFieldDefaulter(fd, fv, field.StructField, fieldPath); err != nil {
+				return err
+			}
+		}
+
+		// Scalars have nothing below them, so the defaults of their own types are excluded by nodefault, too.
+		if field.opts.noDefault && isScalar(derefType(field.Type).Kind()) {
+			continue
+		}
+
+		if err := d.applyDefaultsRecursive(fv, fieldPath); err != nil {
 			return err
 		}
+
+		if d.tagDefaults && !field.opts.noDefault {
+			if err := d.applyTagDefault(fv, field.StructField, fieldPath); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func handleSlice(v reflect.Value, defaults map[reflect.Type][]any, visited map[uintptr]bool) error {
+// skipFields reports whether applying defaults to the given fields of the struct type t is a no-op, which is the case
+// for flat structs whose fields have no defaults of their own. The report counts visited fields, so it disables the
+// fast path.
+func (d *defaulter) skipFields(t reflect.Type, fields []fieldInfo) bool {
+	if d.report != nil || d.tagDefaults || !d.tags.flat(t, fields) {
+		return false
+	}
+
+	for _, field := range fields {
+		if len(d.defaults[field.Type]) > 0 || len(d.computed[field.Type]) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasDefaults reports whether defaults are registered for the struct type t.
+func (d *defaulter) hasDefaults(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && len(d.typeDefaults(t)) > 0
+}
+
+// handleSlice applies defaults to the elements of a slice or array. Slice elements are addressable, so they are
+// defaulted in place, reusing the slice's backing array. Only the concrete values stored in interface elements have to
+// be copied.
+func (d *defaulter) handleSlice(v reflect.Value, path string) error {
 	for i := range v.Len() {
 		elem := v.Index(i)
-		if elem.Kind() == reflect.Interface && !elem.IsNil() {
-			elem = elem.Elem()
+		if elem.Kind() != reflect.Interface || elem.IsNil() {
+			if err := d.applyDefaultsRecursive(elem, indexPath(path, i)); err != nil {
+				return err
+			}
+
+			continue
 		}
 
+		elem = elem.Elem()
+
 		newElem := reflect.New(elem.Type()).Elem()
 		newElem.Set(elem)
-		if err := applyDefaultsRecursive(newElem, defaults, visited); err != nil {
+		if err := d.applyDefaultsRecursive(newElem, indexPath(path, i)); err != nil {
 			return err
 		}
 
@@ -103,69 +380,167 @@ func handleSlice(v reflect.Value, defaults map[reflect.Type][]any, visited map[u
 	return nil
 }
 
-func handleMap(v reflect.Value, defaults map[reflect.Type][]any, visited map[uintptr]bool) error {
+// handleMap applies defaults to a map and its values. The defaults registered for the map's type are added first, so
+// that the values they add receive the defaults of their own types, too. Map values aren't addressable, so every value
+// is defaulted as a copy, which is stored back, including the structs, slices and maps nested in it.
+func (d *defaulter) handleMap(v reflect.Value, path string) error {
 	if v.IsNil() {
+		if !v.CanSet() {
+			return nil
+		}
+
 		v.Set(reflect.MakeMap(v.Type()))
 	}
 
+	d.applyMapDefaults(v, d.defaults[v.Type()], path)
+
 	for _, key := range v.MapKeys() {
 		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			// Keys that aren't equal to themselves, e.g. NaN, can't be looked up, so their values can't be updated.
+			continue
+		}
+
 		if elem.Kind() == reflect.Interface && !elem.IsNil() {
 			elem = elem.Elem()
 		}
 
 		newElem := reflect.New(elem.Type()).Elem()
 		newElem.Set(elem)
-		if err := applyDefaultsRecursive(newElem, defaults, visited); err != nil {
+		if d.copyPointers && elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			newElem.Set(reflect.New(elem.Type().Elem()))
+			newElem.Elem().Set(elem.Elem())
+		}
+
+		if err := d.applyDefaultsRecursive(newElem, keyPath(path, key)); err != nil {
 			return err
 		}
 
 		v.SetMapIndex(key, newElem)
 	}
 
-	return applyMapDefaults(v, defaults[v.Type()])
+	return nil
 }
 
-func applyMapDefaults(v reflect.Value, defaultValues []any) error {
-	for _, dv := range defaultValues {
+// applyMapDefaults adds the entries of the default maps whose keys are missing in v. The values are copied, so that
+// defaulting them doesn't modify the registered defaults.
+func (d *defaulter) applyMapDefaults(v reflect.Value, defaultValues []any, path string) {
+	for i, dv := range defaultValues {
+		d.origin = registeredDefault{value: dv, index: i}
+		d.match()
+
 		defaultMap := reflect.ValueOf(dv)
 		for _, key := range defaultMap.MapKeys() {
-			if !v.MapIndex(key).IsValid() {
-				v.SetMapIndex(key, defaultMap.MapIndex(key))
+			if !d.containsKey(v, key) {
+				value := cloneValue(defaultMap.MapIndex(key), make(map[pointerKey]reflect.Value))
+				v.SetMapIndex(key, value)
+				d.record(keyPath(path, key), reflect.Value{}, value)
 			}
 		}
 	}
+}
 
-	return nil
+// handlePointer applies defaults to the value v points to. In pointer chains like **T, nil pointers behind a non-nil
+// one are allocated if there are defaults for T, as the non-nil pointer shows the value is meant to be set.
+func (d *defaulter) handlePointer(v reflect.Value, path string) error {
+	if v.IsNil() {
+		return nil
+	}
+
+	elem := v.Elem()
+	if elem.Kind() == reflect.Ptr && elem.IsNil() && elem.CanSet() && d.hasDefaults(derefType(elem.Type())) {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+
+	return d.applyDefaultsRecursive(elem, path)
 }
 
-func handlePointer(v reflect.Value, defaults map[reflect.Type][]any, visited map[uintptr]bool) error {
+func (d *defaulter) handleInterface(v reflect.Value, path string) error {
 	if !v.IsNil() {
-		return applyDefaultsRecursive(v.Elem(), defaults, visited)
+		elem := v.Elem()
+		if elem.Kind() == reflect.Ptr || !v.CanSet() {
+			return d.applyDefaultsRecursive(elem, path)
+		}
+
+		// Values stored in interfaces aren't addressable, so they are defaulted as a copy, which is stored back.
+		newElem := reflect.New(elem.Type()).Elem()
+		newElem.Set(elem)
+		if err := d.applyDefaultsRecursive(newElem, path); err != nil {
+			return err
+		}
+
+		v.Set(newElem)
+
+		return nil
+	}
+
+	if !v.CanSet() {
+		return nil
+	}
+
+	concrete, ok := d.interfaceDefault(v.Type())
+	if !ok {
+		return nil
 	}
 
+	if err := d.applyDefaultsRecursive(concrete, path); err != nil {
+		return err
+	}
+
+	v.Set(concrete)
+
 	return nil
 }
 
-func handleInterface(v reflect.Value, defaults map[reflect.Type][]any, visited map[uintptr]bool) error {
-	if !v.IsNil() {
-		return applyDefaultsRecursive(v.Elem(), defaults, visited)
+// interfaceDefault returns a new, settable value of the default type implementing the given interface. Empty
+// interfaces are implemented by every type, so they are never defaulted. If none or more than one of the registered
+// default types implement the interface, the choice is ambiguous and ok is false.
+func (d *defaulter) interfaceDefault(iface reflect.Type) (reflect.Value, bool) {
+	if iface.NumMethod() == 0 {
+		return reflect.Value{}, false
 	}
 
-	return nil
+	var match reflect.Type
+	for t, values := range d.defaults {
+		if len(values) == 0 || !t.Implements(iface) {
+			continue
+		}
+
+		if match != nil {
+			return reflect.Value{}, false
+		}
+		match = t
+	}
+
+	if match == nil {
+		return reflect.Value{}, false
+	}
+
+	concrete := reflect.New(match).Elem()
+	if match.Kind() == reflect.Ptr {
+		concrete.Set(reflect.New(match.Elem()))
+	}
+
+	return concrete, true
 }
 
 // mergeDefault applies default values from src to dst, but only for zero-value fields in dst.
-func mergeDefault(dst, src reflect.Value) error {
+func (d *defaulter) mergeDefault(dst, src reflect.Value, path string) error {
 	dst = dereference(dst)
 	src = dereference(src)
 
+	if isNamedScalar(src.Type()) && src.Type() == dst.Type() {
+		d.mergeScalar(dst, src, path)
+		return nil
+	}
+
 	if src.Kind() != reflect.Struct || dst.Kind() != reflect.Struct {
 		return nil
 	}
 
-	for i := range src.NumField() {
-		if err := mergeField(dst.Field(i), src.Field(i), dst.Type().Field(i)); err != nil {
+	for i, field := range d.tags.fields(dst.Type()) {
+		fieldPath := joinPath(path, d.tags.fieldName(field.StructField))
+		if err := d.mergeField(dst.Field(i), src.Field(i), field, fieldPath); err != nil {
 			return err
 		}
 	}
@@ -173,24 +548,92 @@ func mergeDefault(dst, src reflect.Value) error {
 	return nil
 }
 
-func mergeField(dst, src reflect.Value, structField reflect.StructField) error {
-	if !structField.IsExported() {
+// mergeScalar applies the default of a named scalar type in src to dst, if dst is unset.
+func (d *defaulter) mergeScalar(dst, src reflect.Value, path string) {
+	if !dst.CanSet() || src.IsZero() {
+		return
+	}
+
+	if d.overwrite {
+		if d.isWritten(path) {
+			return
+		}
+		d.written[path] = true
+	} else if !d.isZero(dst) {
+		return
+	}
+
+	before := reflect.New(dst.Type()).Elem()
+	before.Set(dst)
+	dst.Set(src)
+	d.record(path, before, dst)
+}
+
+func (d *defaulter) mergeField(dst, src reflect.Value, field fieldInfo, path string) error {
+	// Fields of values reached through unexported fields can't be set, so their defaults are skipped, see
+	// findUnexportedDefaults. The fields of unexported embedded structs can be set, though.
+	if !field.IsExported() || !dst.CanSet() {
+		return nil
+	}
+
+	if field.err != nil {
+		return wrapPath(path, field.err)
+	}
+
+	opts := field.opts
+	if opts.skip || opts.noDefault {
+		return nil
+	}
+
+	if d.overwrite {
+		return d.overwriteField(dst, src, path)
+	}
+
+	if opts.merge == mergeAdd {
+		before := reflect.ValueOf(dst.Interface())
+		if err := addField(dst, src, field.StructField); err != nil {
+			return wrapPath(path, err)
+		}
+		d.record(path, before, dst)
+
 		return nil
 	}
 
-	if dst.IsZero() {
-		return setField(dst, src)
+	if d.isZero(dst) {
+		if src.IsZero() {
+			if dst.IsZero() {
+				// Filling zero values with zero defaults allocates nil maps in the defaulted data-structure.
+				return setField(dst, src)
+			}
+
+			// Values treated as zero by a custom zero func are kept unless there is a value to replace them with.
+			return nil
+		}
+
+		before := reflect.New(dst.Type()).Elem()
+		before.Set(dst)
+
+		if err := setField(dst, src); err != nil {
+			return wrapPath(path, err)
+		}
+		d.record(path, before, dst)
+
+		return nil
+	}
+
+	if opts.weakRef {
+		return nil
 	}
 
 	//nolint:exhaustive // Only merging struct, ptr, and map fields; other types are handled by the default zero-value
 	//                  // check
 	switch src.Kind() {
 	case reflect.Struct:
-		return mergeDefault(dst, src)
+		return d.mergeDefault(dst, src, path)
 	case reflect.Ptr:
-		return mergePtrField(dst, src)
+		return d.mergePtrField(dst, src, path)
 	case reflect.Map:
-		return mergeMapField(dst, src)
+		return d.mergeMapField(dst, src, path)
 	default:
 		// Other kinds don't need special handling
 	}
@@ -198,7 +641,25 @@ func mergeField(dst, src reflect.Value, structField reflect.StructField) error {
 	return nil
 }
 
-func mergePtrField(dst, src reflect.Value) error {
+// addField adds the numeric default in src to the value of dst.
+func addField(dst, src reflect.Value, structField reflect.StructField) error {
+	//nolint:exhaustive // Only numeric kinds can be added up
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetInt(dst.Int() + src.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		dst.SetUint(dst.Uint() + src.Uint())
+	case reflect.Float32, reflect.Float64:
+		dst.SetFloat(dst.Float() + src.Float())
+	default:
+		return fmt.Errorf("%w: %w: merge=add requires a numeric field, but %s is of kind %s",
+			ErrInvalidTag, ErrUnsupportedDefaultKind, structField.Name, dst.Kind())
+	}
+
+	return nil
+}
+
+func (d *defaulter) mergePtrField(dst, src reflect.Value, path string) error {
 	if src.IsNil() || src.Elem().Kind() != reflect.Struct {
 		return nil
 	}
@@ -207,23 +668,45 @@ func mergePtrField(dst, src reflect.Value) error {
 		dst.Set(reflect.New(src.Elem().Type()))
 	}
 
-	return mergeDefault(dst.Elem(), src.Elem())
+	return d.mergeDefault(dst.Elem(), src.Elem(), path)
 }
 
-func mergeMapField(dst, src reflect.Value) error {
+func (d *defaulter) mergeMapField(dst, src reflect.Value, path string) error {
 	if dst.IsNil() {
 		return nil
 	}
 
 	for _, key := range src.MapKeys() {
-		if !dst.MapIndex(key).IsValid() {
-			dst.SetMapIndex(key, src.MapIndex(key))
+		if !d.containsKey(dst, key) {
+			value := cloneValue(src.MapIndex(key), make(map[pointerKey]reflect.Value))
+			dst.SetMapIndex(key, value)
+			d.record(keyPath(path, key), reflect.Value{}, value)
 		}
 	}
 
 	return nil
 }
 
+// containsKey reports whether the map m contains the key, comparing string keys case-insensitively if enabled.
+func (d *defaulter) containsKey(m, key reflect.Value) bool {
+	if m.MapIndex(key).IsValid() {
+		return true
+	}
+
+	if !d.foldMapKeys || key.Kind() != reflect.String {
+		return false
+	}
+
+	iter := m.MapRange()
+	for iter.Next() {
+		if strings.EqualFold(iter.Key().String(), key.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func dereference(v reflect.Value) reflect.Value {
 	if v.Kind() == reflect.Ptr {
 		return v.Elem()
@@ -233,16 +716,77 @@ func dereference(v reflect.Value) reflect.Value {
 }
 
 func setField(dst, src reflect.Value) error {
-	if src.Kind() == reflect.Map && dst.IsNil() {
-		dst.Set(reflect.MakeMap(src.Type()))
-		for _, key := range src.MapKeys() {
-			dst.SetMapIndex(key, src.MapIndex(key))
+	if src.Kind() == reflect.String && !src.Type().AssignableTo(dst.Type()) {
+		if target := textualTarget(dst.Type()); target != nil {
+			// String defaults are parsed into fields of textual types, e.g. custom enums, net.IP or url.URL, and
+			// pointers to them.
+			value := reflect.New(target).Elem()
+			if err := convert.SetString(value, src.String()); err != nil {
+				return fmt.Errorf("%w: %q into %s: %w", ErrDefaultParse, src.String(), dst.Type(), err)
+			}
+
+			if dst.Kind() == reflect.Ptr {
+				value = value.Addr()
+			}
+			dst.Set(value)
+
+			return nil
 		}
+	}
 
-		return nil
+	if !src.Type().AssignableTo(dst.Type()) {
+		return fmt.Errorf("%w: default of type %s is not assignable to field of type %s",
+			ErrTypeMismatch, src.Type(), dst.Type())
 	}
 
-	dst.Set(src)
+	// The default is copied, so that later defaults merged into the field don't modify the registered default, which
+	// may be used by concurrent builds.
+	dst.Set(cloneValue(src, make(map[pointerKey]reflect.Value)))
 
 	return nil
 }
+
+// textualTarget returns the type string defaults are parsed into for fields of type t, i.e. t itself or the type t
+// points to, if it's a textual type, see convert.IsTextual. Otherwise, it returns nil.
+func textualTarget(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if !convert.IsTextual(t) {
+		return nil
+	}
+
+	return t
+}
+
+// isZero reports whether v is unset and gets filled by defaults, consulting the zero func registered for its type.
+func (d *defaulter) isZero(v reflect.Value) bool {
+	if fn := d.zeroFuncs[v.Type()]; fn != nil {
+		return fn(v)
+	}
+
+	if d.emptyAsUnset && (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) {
+		return v.Len() == 0
+	}
+
+	return v.IsZero()
+}
+
+// match marks the default currently being merged as applied in the report, if there is one.
+func (d *defaulter) match() {
+	if d.report == nil || d.origin.index < 0 || d.origin.value == nil {
+		return
+	}
+
+	d.report.match(defaultKey{scope: d.scope, typ: reflect.TypeOf(d.origin.value), index: d.origin.index})
+}
+
+// record adds a change made by the current default to the report, if there is one.
+func (d *defaulter) record(path string, before, after reflect.Value) {
+	if d.report == nil {
+		return
+	}
+
+	d.report.Changes = append(d.report.Changes, newChange(path, before, after, d.origin))
+}
@@ -2,6 +2,11 @@
 package konfetty
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -62,10 +67,40 @@ func TestApplyDefaults(t *testing.T) {
 		testEmbeddedStructs(t)
 	})
 
+	t.Run("Embedded Pointer Structs", func(t *testing.T) {
+		t.Parallel()
+		testEmbeddedPointerStructs(t)
+	})
+
 	t.Run("Slices of Interfaces", func(t *testing.T) {
 		t.Parallel()
 		testSlicesOfInterfaces(t)
 	})
+
+	t.Run("Pointer Map Values", func(t *testing.T) {
+		t.Parallel()
+		testPointerMapValues(t)
+	})
+
+	t.Run("Nil Interface Fields", func(t *testing.T) {
+		t.Parallel()
+		testNilInterfaceFields(t)
+	})
+
+	t.Run("Nested Containers", func(t *testing.T) {
+		t.Parallel()
+		testNestedContainers(t)
+	})
+
+	t.Run("Map Struct Values", func(t *testing.T) {
+		t.Parallel()
+		testMapStructValues(t)
+	})
+
+	t.Run("Interface Values", func(t *testing.T) {
+		t.Parallel()
+		testInterfaceValues(t)
+	})
 }
 
 func TestApplyDefaultsErrors(t *testing.T) {
@@ -205,6 +240,15 @@ func testSlicesAndPointers(t *testing.T) {
 		Ptr *SimpleStruct
 	}
 
+	type DoublePointerStruct struct {
+		Ptr **SimpleStruct
+	}
+
+	set := &SimpleStruct{Name: "Set"}
+	setDefaulted := &SimpleStruct{Name: "Set", Age: 30}
+	var unset *SimpleStruct
+	unsetDefaulted := &SimpleStruct{Name: "Default", Age: 30}
+
 	tests := []struct {
 		name     string
 		config   any
@@ -255,6 +299,36 @@ func testSlicesAndPointers(t *testing.T) {
 			},
 			expected: &PointerStruct{},
 		},
+		{
+			name:   "Double pointer to struct",
+			config: &DoublePointerStruct{Ptr: &set},
+			defaults: map[reflect.Type][]any{
+				reflect.TypeOf(SimpleStruct{}): {
+					SimpleStruct{Name: "Default", Age: 30},
+				},
+			},
+			expected: &DoublePointerStruct{Ptr: &setDefaulted},
+		},
+		{
+			name:   "Double pointer to nil pointer",
+			config: &DoublePointerStruct{Ptr: &unset},
+			defaults: map[reflect.Type][]any{
+				reflect.TypeOf(SimpleStruct{}): {
+					SimpleStruct{Name: "Default", Age: 30},
+				},
+			},
+			expected: &DoublePointerStruct{Ptr: &unsetDefaulted},
+		},
+		{
+			name:   "Nil double pointer",
+			config: &DoublePointerStruct{},
+			defaults: map[reflect.Type][]any{
+				reflect.TypeOf(SimpleStruct{}): {
+					SimpleStruct{Name: "Default", Age: 30},
+				},
+			},
+			expected: &DoublePointerStruct{},
+		},
 	}
 
 	for _, tt := range tests {
@@ -439,7 +513,8 @@ func testCircularReferences(t *testing.T) {
 
 	err := applyDefaults(config, defaults)
 	must.Error(t, err)
-	must.Eq(t, ErrCircularReference, err)
+	must.ErrorIs(t, err, ErrCircularReference)
+	must.ErrorContains(t, err, "Next.Next.Next")
 
 	must.Eq(t, "Start", config.Name)
 	must.Eq(t, "Middle", config.Next.Name)
@@ -484,6 +559,63 @@ func testInterfaceFields(t *testing.T) {
 	must.Eq(t, "nested", nestedData.Value)
 }
 
+func testMapStructValues(t *testing.T) {
+	type Device struct {
+		Name  string
+		Power int
+	}
+
+	type RoomConfig struct {
+		Name    string
+		Devices []Device
+		Labels  map[string]Device
+	}
+
+	type Config struct {
+		Rooms map[string]RoomConfig
+	}
+
+	defaultRooms := map[string]RoomConfig{
+		"hall": {Devices: []Device{{Name: "lamp"}}},
+	}
+
+	config := &Config{
+		Rooms: map[string]RoomConfig{
+			"kitchen": {
+				Devices: []Device{{Name: "oven"}, {}},
+				Labels:  map[string]Device{"main": {}},
+			},
+		},
+	}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Device{}):     {Device{Name: "device", Power: 1}},
+		reflect.TypeOf(RoomConfig{}): {RoomConfig{Name: "room"}},
+		reflect.TypeOf(defaultRooms): {defaultRooms},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+	must.Eq(t, &Config{
+		Rooms: map[string]RoomConfig{
+			"kitchen": {
+				Name:    "room",
+				Devices: []Device{{Name: "oven", Power: 1}, {Name: "device", Power: 1}},
+				Labels:  map[string]Device{"main": {Name: "device", Power: 1}},
+			},
+			// Values added by the defaults of the map type receive the defaults of their own types, too.
+			"hall": {
+				Name:    "room",
+				Devices: []Device{{Name: "lamp", Power: 1}},
+				Labels:  map[string]Device{},
+			},
+		},
+	}, config)
+
+	// The registered default is copied, not modified.
+	must.Eq(t, map[string]RoomConfig{"hall": {Devices: []Device{{Name: "lamp"}}}}, defaultRooms)
+}
+
 func testMaps(t *testing.T) {
 	type SimpleStruct struct {
 		Name string
@@ -580,6 +712,67 @@ func testEmbeddedStructs(t *testing.T) {
 	must.True(t, config.TopLevelField)
 }
 
+func testEmbeddedPointerStructs(t *testing.T) {
+	type EmbeddedLevel1 struct {
+		Level1Field string
+		Count       int
+	}
+
+	type EmbeddedLevel2 struct {
+		*EmbeddedLevel1
+		Level2Field int
+	}
+
+	type Unmatched struct {
+		Value string
+	}
+
+	type Config struct {
+		*EmbeddedLevel2
+		*Unmatched
+	}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(EmbeddedLevel1{}): {
+			EmbeddedLevel1{Level1Field: "default level 1", Count: 1},
+		},
+		reflect.TypeOf(&EmbeddedLevel2{}): {
+			&EmbeddedLevel2{Level2Field: 42},
+		},
+	}
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{}
+
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+
+		must.NotNil(t, config.EmbeddedLevel2)
+		must.NotNil(t, config.EmbeddedLevel1)
+		must.Eq(t, "default level 1", config.Level1Field)
+		must.Eq(t, 42, config.Level2Field)
+
+		// Embedded pointers without matching defaults stay nil.
+		must.Nil(t, config.Unmatched)
+	})
+
+	t.Run("NonNil", func(t *testing.T) {
+		t.Parallel()
+
+		level1 := &EmbeddedLevel1{Count: 5}
+		config := &Config{EmbeddedLevel2: &EmbeddedLevel2{EmbeddedLevel1: level1}}
+
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+
+		must.True(t, config.EmbeddedLevel1 == level1)
+		must.Eq(t, EmbeddedLevel1{Level1Field: "default level 1", Count: 5}, *level1)
+		must.Eq(t, 42, config.Level2Field)
+	})
+}
+
 type Animal interface {
 	Sound() string
 }
@@ -634,3 +827,776 @@ func testSlicesOfInterfaces(t *testing.T) {
 	must.True(t, ok)
 	must.Eq(t, "DefaultCat", cat.Name)
 }
+
+// testInterfaceValues checks that struct values stored in interfaces, which aren't addressable, receive defaults no
+// matter how deeply they are nested, and that values behind unexported fields are skipped without panicking.
+func testInterfaceValues(t *testing.T) {
+	type LightDevice struct {
+		Name       string
+		Brightness int
+		Labels     map[string]string
+	}
+
+	type Holder struct {
+		Device any
+		hidden LightDevice
+	}
+
+	type Config struct {
+		Devices []any
+		Named   map[string]any
+		Fixed   [1]any
+		Holder  any
+		hidden  Holder
+	}
+
+	config := &Config{
+		Devices: []any{
+			LightDevice{Name: "lamp"},
+			&LightDevice{},
+			[]any{LightDevice{}},
+			map[string]any{"nested": LightDevice{}},
+		},
+		Named:  map[string]any{"hall": LightDevice{Brightness: 10}, "holder": Holder{Device: LightDevice{}}},
+		Fixed:  [1]any{LightDevice{}},
+		Holder: Holder{Device: [1]any{LightDevice{}}},
+		hidden: Holder{Device: LightDevice{}},
+	}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(LightDevice{}): {LightDevice{Name: "light", Brightness: 50}},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+
+	defaulted := LightDevice{Name: "light", Brightness: 50, Labels: map[string]string{}}
+	must.Eq(t, []any{
+		LightDevice{Name: "lamp", Brightness: 50, Labels: map[string]string{}},
+		&defaulted,
+		[]any{defaulted},
+		map[string]any{"nested": defaulted},
+	}, config.Devices)
+	must.Eq(t, map[string]any{
+		"hall":   LightDevice{Name: "light", Brightness: 10, Labels: map[string]string{}},
+		"holder": Holder{Device: defaulted},
+	}, config.Named)
+	must.Eq(t, [1]any{defaulted}, config.Fixed)
+	must.Eq[any](t, Holder{Device: [1]any{defaulted}}, config.Holder)
+	must.Eq[any](t, LightDevice{}, config.hidden.Device)
+}
+
+func testNilInterfaceFields(t *testing.T) {
+	type Home struct {
+		Pet   Animal
+		Other Animal
+		Any   any
+	}
+
+	t.Run("Value Default", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Home{Other: &Cat{}}
+		defaults := map[reflect.Type][]any{
+			reflect.TypeOf(Dog{}):  {Dog{Name: "Rex"}},
+			reflect.TypeOf(&Cat{}): {},
+		}
+
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+
+		// The only default type implementing Animal is assigned to the nil field, empty interfaces are left alone.
+		must.Eq[Animal](t, Dog{Name: "Rex"}, config.Pet)
+		must.Eq[Animal](t, &Cat{}, config.Other)
+		must.Nil(t, config.Any)
+	})
+
+	t.Run("Pointer Default", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Home{}
+		defaults := map[reflect.Type][]any{
+			reflect.TypeOf(&Cat{}): {&Cat{Name: "Tom"}},
+		}
+
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+		must.Eq[Animal](t, &Cat{Name: "Tom"}, config.Pet)
+	})
+
+	t.Run("Ambiguous", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Home{}
+		defaults := map[reflect.Type][]any{
+			reflect.TypeOf(Dog{}): {Dog{Name: "Rex"}},
+			reflect.TypeOf(Cat{}): {Cat{Name: "Tom"}},
+		}
+
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+		must.Nil(t, config.Pet)
+	})
+}
+
+func testNestedContainers(t *testing.T) {
+	type Device struct {
+		Name  string
+		Power int
+	}
+
+	type Routine struct {
+		Name  string
+		Steps int
+	}
+
+	type Home struct {
+		Floors    []map[string]Device
+		Routines  map[string][]Routine
+		Pointers  map[string][]*Routine
+		Grid      [][]Device
+		Zones     map[string]map[string]Device
+		Mixed     []map[string]any
+		Schedules map[string][2]Routine
+	}
+
+	config := &Home{
+		Floors:    []map[string]Device{{"lamp": {Name: "Lamp"}}, nil, {"fan": {Power: 5}}},
+		Routines:  map[string][]Routine{"morning": {{Name: "Wake"}, {}}},
+		Pointers:  map[string][]*Routine{"evening": {{Steps: 3}, nil}},
+		Grid:      [][]Device{{{}, {Name: "Corner"}}},
+		Zones:     map[string]map[string]Device{"garden": {"sprinkler": {}}},
+		Mixed:     []map[string]any{{"device": Device{}, "routine": &Routine{}, "other": 1}},
+		Schedules: map[string][2]Routine{"weekly": {{Name: "Clean"}, {}}},
+	}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Device{}):  {Device{Name: "Device", Power: 1}},
+		reflect.TypeOf(Routine{}): {Routine{Name: "Routine", Steps: 1}},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+
+	device := Device{Name: "Device", Power: 1}
+	routine := Routine{Name: "Routine", Steps: 1}
+
+	must.Eq(t, []map[string]Device{
+		{"lamp": {Name: "Lamp", Power: 1}}, {}, {"fan": {Name: "Device", Power: 5}},
+	}, config.Floors)
+	must.Eq(t, map[string][]Routine{"morning": {{Name: "Wake", Steps: 1}, routine}}, config.Routines)
+	must.Eq(t, map[string][]*Routine{"evening": {{Name: "Routine", Steps: 3}, nil}}, config.Pointers)
+	must.Eq(t, [][]Device{{device, {Name: "Corner", Power: 1}}}, config.Grid)
+	must.Eq(t, map[string]map[string]Device{"garden": {"sprinkler": device}}, config.Zones)
+	must.Eq(t, []map[string]any{{"device": device, "routine": &routine, "other": 1}}, config.Mixed)
+	must.Eq(t, map[string][2]Routine{"weekly": {{Name: "Clean", Steps: 1}, routine}}, config.Schedules)
+}
+
+func TestApplyDefaultsMergeAdd(t *testing.T) {
+	t.Parallel()
+
+	type Quota struct {
+		Requests int     `konfetty:"merge=add"`
+		Storage  float64 `konfetty:"merge=add"`
+		Burst    uint    `konfetty:"merge=add"`
+		Limit    int
+	}
+
+	config := &Quota{Requests: 100, Storage: 1.5, Limit: 10}
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Quota{}): {
+			Quota{Requests: 50, Storage: 0.25, Burst: 5, Limit: 20},
+		},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+	must.Eq(t, &Quota{Requests: 150, Storage: 1.75, Burst: 5, Limit: 10}, config)
+}
+
+func TestApplyDefaultsMergeAddErrors(t *testing.T) {
+	t.Parallel()
+
+	type NonNumeric struct {
+		Name string `konfetty:"merge=add"`
+	}
+
+	type UnknownStrategy struct {
+		Count int `konfetty:"merge=multiply"`
+	}
+
+	tests := []struct {
+		name     string
+		config   any
+		defaults map[reflect.Type][]any
+	}{
+		{
+			name:   "Non-numeric field",
+			config: &NonNumeric{},
+			defaults: map[reflect.Type][]any{
+				reflect.TypeOf(NonNumeric{}): {NonNumeric{Name: "Default"}},
+			},
+		},
+		{
+			name:   "Unknown strategy",
+			config: &UnknownStrategy{},
+			defaults: map[reflect.Type][]any{
+				reflect.TypeOf(UnknownStrategy{}): {UnknownStrategy{Count: 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := applyDefaults(tt.config, tt.defaults)
+			must.ErrorIs(t, err, ErrInvalidTag)
+		})
+	}
+}
+
+func testPointerMapValues(t *testing.T) {
+	type EndpointConfig struct {
+		URL     string
+		Timeout time.Duration
+	}
+
+	type Config struct {
+		Endpoints map[string]*EndpointConfig
+		Primary   *EndpointConfig
+		Fallback  *EndpointConfig
+	}
+
+	shared := &EndpointConfig{URL: "http://shared"}
+	config := &Config{
+		Endpoints: map[string]*EndpointConfig{
+			"a":     shared,
+			"b":     shared,
+			"c":     {Timeout: time.Minute},
+			"empty": nil,
+		},
+	}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(EndpointConfig{}): {
+			EndpointConfig{URL: "http://default", Timeout: time.Second},
+		},
+	}
+
+	t.Run("Shared pointers", func(t *testing.T) {
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+
+		must.Eq(t, &EndpointConfig{URL: "http://shared", Timeout: time.Second}, config.Endpoints["a"])
+		must.Eq(t, &EndpointConfig{URL: "http://shared", Timeout: time.Second}, config.Endpoints["b"])
+		must.Eq(t, &EndpointConfig{URL: "http://default", Timeout: time.Minute}, config.Endpoints["c"])
+		must.Nil(t, config.Endpoints["empty"])
+		must.Nil(t, config.Primary)
+		must.Nil(t, config.Fallback)
+	})
+
+	t.Run("Copied pointers", func(t *testing.T) {
+		original := &EndpointConfig{}
+		config := &Config{
+			Endpoints: map[string]*EndpointConfig{
+				"a": original,
+				"b": original,
+			},
+		}
+
+		d := &defaulter{defaults: defaults, copyPointers: true}
+		err := d.apply(config)
+		must.NoError(t, err)
+
+		must.Eq(t, &EndpointConfig{URL: "http://default", Timeout: time.Second}, config.Endpoints["a"])
+		must.Eq(t, &EndpointConfig{URL: "http://default", Timeout: time.Second}, config.Endpoints["b"])
+		must.False(t, config.Endpoints["a"] == config.Endpoints["b"])
+		must.Eq(t, &EndpointConfig{}, original)
+	})
+}
+
+func TestApplyDefaultsErrorPath(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Model string
+		Name  string `konfetty:"merge=add"`
+	}
+
+	type Room struct {
+		Devices []Device
+	}
+
+	type Config struct {
+		Rooms map[string]Room
+		Main  Device
+	}
+
+	tests := []struct {
+		name     string
+		defaults map[reflect.Type][]any
+		expected string
+	}{
+		{
+			name:     "Nested Field",
+			defaults: map[reflect.Type][]any{reflect.TypeOf(Config{}): {Config{Main: Device{Name: "main"}}}},
+			expected: "Main.Name: ",
+		},
+		{
+			name:     "Map and Slice Elements",
+			defaults: map[reflect.Type][]any{reflect.TypeOf(Device{}): {Device{Name: "device"}}},
+			expected: "Rooms[kitchen].Devices[0].Name: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Config{
+				Rooms: map[string]Room{"kitchen": {Devices: []Device{{}}}},
+				Main:  Device{Model: "hub"},
+			}
+
+			err := applyDefaults(config, tt.defaults)
+			must.ErrorIs(t, err, ErrInvalidTag)
+			must.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestApplyDefaultsPointerKeying(t *testing.T) {
+	t.Parallel()
+
+	type LightDevice struct {
+		Name       string
+		Brightness int `konfetty:"merge=add"`
+	}
+
+	type Config struct {
+		Main    *LightDevice
+		Backup  LightDevice
+		Devices []LightDevice
+		Extras  []*LightDevice
+	}
+
+	tests := []struct {
+		name     string
+		defaults map[reflect.Type][]any
+	}{
+		{
+			name:     "Value Default",
+			defaults: map[reflect.Type][]any{reflect.TypeOf(LightDevice{}): {LightDevice{Name: "light", Brightness: 10}}},
+		},
+		{
+			name:     "Pointer Default",
+			defaults: map[reflect.Type][]any{reflect.TypeOf(&LightDevice{}): {&LightDevice{Name: "light", Brightness: 10}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Config{
+				Main:    &LightDevice{},
+				Devices: []LightDevice{{}, {Name: "desk"}},
+				Extras:  []*LightDevice{{}},
+			}
+
+			err := applyDefaults(config, tt.defaults)
+			must.NoError(t, err)
+
+			// Every device receives the default exactly once, no matter if it's reached through a pointer or not.
+			expected := LightDevice{Name: "light", Brightness: 10}
+			must.Eq(t, &expected, config.Main)
+			must.Eq(t, expected, config.Backup)
+			must.Eq(t, []LightDevice{expected, {Name: "desk", Brightness: 10}}, config.Devices)
+			must.Eq(t, []*LightDevice{&expected}, config.Extras)
+		})
+	}
+
+	t.Run("Precedence", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{}
+		defaults := map[reflect.Type][]any{
+			reflect.TypeOf(LightDevice{}):  {LightDevice{Name: "value"}},
+			reflect.TypeOf(&LightDevice{}): {&LightDevice{Name: "pointer"}},
+		}
+
+		err := applyDefaults(config, defaults)
+		must.NoError(t, err)
+		must.Eq(t, "value", config.Backup.Name)
+	})
+}
+
+func TestApplyDefaultsSliceBackingArray(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string
+	}
+
+	type Config struct {
+		Items []Item
+	}
+
+	items := make([]Item, 2, 4)
+	items[1].Name = "set"
+	config := &Config{Items: items}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Item{}): {Item{Name: "default"}},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+
+	// The elements are defaulted in place, so the caller's slice sees the defaults, too.
+	must.True(t, &config.Items[0] == &items[0])
+	must.Eq(t, 4, cap(config.Items))
+	must.Eq(t, []Item{{Name: "default"}, {Name: "set"}}, items)
+}
+
+func BenchmarkApplyDefaultsSlice(b *testing.B) {
+	type Item struct {
+		Name  string
+		Count int
+		Tags  map[string]string
+	}
+
+	type Config struct {
+		Items []Item
+	}
+
+	config := &Config{Items: make([]Item, 1000)}
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Item{}): {Item{Name: "default", Count: 1, Tags: map[string]string{"a": "b"}}},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		if err := applyDefaults(config, defaults); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type flatPort int
+
+func TestApplyDefaultsFlat(t *testing.T) {
+	t.Parallel()
+
+	type Flat struct {
+		Name    string
+		Port    flatPort
+		Timeout time.Duration
+		Debug   bool
+	}
+
+	type Nested struct {
+		Flat Flat
+	}
+
+	tags := tagResolver{keys: []string{"konfetty"}, cache: &typeCache{}}
+	flatType, nestedType := reflect.TypeFor[Flat](), reflect.TypeFor[Nested]()
+	must.True(t, tags.flat(flatType, tags.fields(flatType)))
+	must.False(t, tags.flat(nestedType, tags.fields(nestedType)))
+
+	t.Run("FastPath", func(t *testing.T) {
+		t.Parallel()
+
+		d := &defaulter{
+			defaults: map[reflect.Type][]any{reflect.TypeFor[Flat](): {Flat{Name: "app", Timeout: time.Second}}},
+			tags:     tags,
+		}
+		must.True(t, d.skipFields(flatType, tags.fields(flatType)))
+
+		config := &Flat{Name: "set"}
+		must.NoError(t, d.apply(config))
+		must.Eq(t, Flat{Name: "set", Timeout: time.Second}, *config)
+	})
+
+	t.Run("FieldTypeDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		// Defaults of the field types have to be applied, so the fields are recursed into.
+		d := &defaulter{
+			defaults: map[reflect.Type][]any{
+				reflect.TypeFor[Flat]():     {Flat{Name: "app"}},
+				reflect.TypeFor[flatPort](): {flatPort(8080)},
+			},
+			tags: tags,
+		}
+		must.False(t, d.skipFields(flatType, tags.fields(flatType)))
+
+		config := &Nested{}
+		must.NoError(t, d.apply(config))
+		must.Eq(t, Flat{Name: "app", Port: 8080}, config.Flat)
+	})
+}
+
+func BenchmarkApplyDefaultsFlat(b *testing.B) {
+	type Config struct {
+		Host    string
+		Port    int
+		Timeout time.Duration
+		Debug   bool
+		Retries int
+		Name    string
+	}
+
+	// Frequent builds use the type cache, so the field metadata isn't resolved over and over again.
+	d := &defaulter{
+		defaults: map[reflect.Type][]any{
+			reflect.TypeFor[Config](): {Config{Host: "localhost", Port: 8080, Timeout: time.Second, Retries: 3}},
+		},
+		tags: tagResolver{keys: []string{"konfetty"}, cache: &typeCache{}},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		config := &Config{Name: "app"}
+		if err := d.apply(config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestApplyDefaultsWeakRef(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name     string
+		Parent   *Node `konfetty:"weakref"`
+		Children []*Node
+		Peers    []*Node `konfetty:"weakref"`
+	}
+
+	root := &Node{Name: "root"}
+	left := &Node{Parent: root}
+	right := &Node{Parent: root}
+	left.Peers = []*Node{right}
+	right.Peers = []*Node{left}
+	root.Children = []*Node{left, right}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Node{}): {
+			Node{Name: "Default"},
+		},
+	}
+
+	err := applyDefaults(root, defaults)
+	must.NoError(t, err)
+
+	must.Eq(t, "root", root.Name)
+	must.Eq(t, "Default", left.Name)
+	must.Eq(t, "Default", right.Name)
+	must.True(t, left.Parent == root)
+	must.True(t, right.Peers[0] == left)
+}
+
+func TestApplyDefaultsCycleWithoutWeakRef(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name     string
+		Parent   *Node
+		Children []*Node
+	}
+
+	root := &Node{Name: "root"}
+	root.Children = []*Node{{Parent: root}}
+
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Node{}): {
+			Node{Name: "Default"},
+		},
+	}
+
+	err := applyDefaults(root, defaults)
+	must.ErrorIs(t, err, ErrCircularReference)
+}
+
+func TestApplyDefaultsJSONNumber(t *testing.T) {
+	t.Parallel()
+
+	type Limits struct {
+		Max     json.Number
+		Min     json.Number
+		Numbers []json.Number
+	}
+
+	config := &Limits{Min: "0.5", Numbers: []json.Number{"", "7"}}
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Limits{}): {
+			Limits{Max: "1e3", Min: "1"},
+		},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+
+	// json.Number values are replaced atomically and never merged character by character.
+	must.Eq(t, json.Number("1e3"), config.Max)
+	must.Eq(t, json.Number("0.5"), config.Min)
+	must.Eq(t, []json.Number{"", "7"}, config.Numbers)
+
+	maxValue, err := config.Max.Float64()
+	must.NoError(t, err)
+	must.Eq(t, 1000.0, maxValue)
+}
+
+// TestApplyDefaultsBytes doesn't run in parallel, as AllocsPerRun can't be used in parallel tests.
+func TestApplyDefaultsBytes(t *testing.T) {
+	type Payload struct {
+		Raw     json.RawMessage
+		Data    []byte
+		Present []byte
+	}
+
+	large := make([]byte, 1<<20)
+	config := &Payload{Data: large}
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Payload{}): {
+			Payload{Raw: json.RawMessage(`{"a":1}`), Data: []byte("default"), Present: []byte("present")},
+		},
+	}
+
+	err := applyDefaults(config, defaults)
+	must.NoError(t, err)
+
+	// Byte slices are defaulted as a whole, keeping the loaded bytes untouched.
+	must.Eq(t, json.RawMessage(`{"a":1}`), config.Raw)
+	must.Eq(t, []byte("present"), config.Present)
+	must.SliceLen(t, len(large), config.Data)
+
+	// Recursing into every byte would allocate the path of every element.
+	allocs := testing.AllocsPerRun(10, func() {
+		must.NoError(t, applyDefaults(config, defaults))
+	})
+	must.Less(t, 100, allocs)
+
+	visits := 0
+	err = traverse(reflect.ValueOf(config), tagResolver{}, func(reflect.Value, string) error {
+		visits++
+		return nil
+	})
+	must.NoError(t, err)
+	must.Eq(t, 5, visits)
+}
+
+func TestApplyDefaultsCustomMapKeys(t *testing.T) {
+	t.Parallel()
+
+	type DeviceID string
+
+	type Slot int
+
+	type Location struct {
+		Floor int
+		Room  string
+	}
+
+	type Device struct {
+		Name  string
+		Power int
+	}
+
+	type Config struct {
+		ByID       map[DeviceID]Device
+		BySlot     map[Slot]*Device
+		ByLocation map[Location]Device
+		Any        map[DeviceID]any
+		Labels     map[DeviceID]string
+		ByWeight   map[float64]Device
+	}
+
+	config := &Config{
+		ByID:       map[DeviceID]Device{"lamp": {Name: "lamp"}, "fan": {Power: 3}},
+		BySlot:     map[Slot]*Device{1: {}, 2: nil},
+		ByLocation: map[Location]Device{{Floor: 1, Room: "kitchen"}: {}},
+		Any:        map[DeviceID]any{"heater": Device{Name: "heater"}},
+		Labels:     map[DeviceID]string{"lamp": "set"},
+		ByWeight:   map[float64]Device{math.NaN(): {}, 1.5: {}},
+	}
+	defaults := map[reflect.Type][]any{
+		reflect.TypeOf(Device{}): {Device{Name: "device", Power: 1}},
+		reflect.TypeOf(Config{}): {Config{Labels: map[DeviceID]string{"lamp": "default", "fan": "default"}}},
+	}
+
+	d := &defaulter{defaults: defaults, report: &Report{}}
+	must.NoError(t, d.apply(config))
+
+	must.Eq(t, map[DeviceID]Device{"lamp": {Name: "lamp", Power: 1}, "fan": {Name: "device", Power: 3}}, config.ByID)
+	must.Eq(t, Device{Name: "device", Power: 1}, *config.BySlot[1])
+	must.Nil(t, config.BySlot[2])
+	must.MapLen(t, 2, config.BySlot)
+	must.Eq(t, map[Location]Device{{Floor: 1, Room: "kitchen"}: {Name: "device", Power: 1}}, config.ByLocation)
+	must.Eq[any](t, Device{Name: "heater", Power: 1}, config.Any["heater"])
+	must.Eq(t, map[DeviceID]string{"lamp": "set", "fan": "default"}, config.Labels)
+
+	// Values of NaN keys can't be looked up and are left alone.
+	must.MapLen(t, 2, config.ByWeight)
+	must.Eq(t, Device{Name: "device", Power: 1}, config.ByWeight[1.5])
+
+	paths := make([]string, 0, len(d.report.Changes))
+	for _, c := range d.report.Changes {
+		paths = append(paths, c.Path)
+	}
+	must.SliceContains(t, paths, "ByID[fan].Name")
+	must.SliceContains(t, paths, "BySlot[1].Name")
+	must.SliceContains(t, paths, "ByLocation[{1 kitchen}].Power")
+	must.SliceContains(t, paths, "Labels[fan]")
+}
+
+type textLevel int
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	if string(text) != "debug" {
+		return fmt.Errorf("unknown level %q", text)
+	}
+	*l = 1
+
+	return nil
+}
+
+func TestSetFieldTextUnmarshaler(t *testing.T) {
+	t.Parallel()
+
+	var level textLevel
+	must.NoError(t, setField(reflect.ValueOf(&level).Elem(), reflect.ValueOf("debug")))
+	must.Eq(t, 1, level)
+
+	must.ErrorContains(t, setField(reflect.ValueOf(&level).Elem(), reflect.ValueOf("trace")), "unknown level")
+
+	var port int
+	must.ErrorContains(t, setField(reflect.ValueOf(&port).Elem(), reflect.ValueOf("80")), "not assignable")
+}
+
+func TestSetFieldNetworkTypes(t *testing.T) {
+	t.Parallel()
+
+	var endpoint url.URL
+	must.NoError(t, setField(reflect.ValueOf(&endpoint).Elem(), reflect.ValueOf("https://example.com/api")))
+	must.Eq(t, url.URL{Scheme: "https", Host: "example.com", Path: "/api"}, endpoint)
+
+	var proxy *url.URL
+	must.NoError(t, setField(reflect.ValueOf(&proxy).Elem(), reflect.ValueOf("http://proxy:3128")))
+	must.Eq(t, &url.URL{Scheme: "http", Host: "proxy:3128"}, proxy)
+
+	var ip net.IP
+	must.NoError(t, setField(reflect.ValueOf(&ip).Elem(), reflect.ValueOf("192.168.0.1")))
+	must.Eq(t, net.ParseIP("192.168.0.1"), ip)
+
+	var level *textLevel
+	must.NoError(t, setField(reflect.ValueOf(&level).Elem(), reflect.ValueOf("debug")))
+	must.Eq(t, 1, *level)
+
+	must.ErrorIs(t, setField(reflect.ValueOf(&ip).Elem(), reflect.ValueOf("not an ip")), ErrDefaultParse)
+}
@@ -0,0 +1,178 @@
+package konfetty
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// change describes a single value that differs between two versions of a data-structure.
+type change struct {
+	path          string
+	before, after reflect.Value
+}
+
+// Diff compares two versions of a data-structure and returns the values that differ between them, e.g. to log exactly
+// what changed when a config is reloaded. Every change holds the path of the value along with its old value from a and
+// its new value from b. Slice elements and map entries are compared by index and key; elements and entries only
+// present in b are reported as additions with an Old value of nil, the ones only present in a as removals with a New
+// value of nil. The default fields of the returned changes are unset, as they don't stem from defaults.
+//
+//	for _, change := range konfetty.Diff(previous, current) {
+//		log.Printf("config changed: %s", change)
+//	}
+func Diff[T any](a, b *T) []Change {
+	changes := diff(reflect.ValueOf(a), reflect.ValueOf(b), tagResolver{})
+
+	result := make([]Change, 0, len(changes))
+	for _, c := range changes {
+		result = append(result, newChange(c.path, c.before, c.after, registeredDefault{}))
+	}
+
+	return result
+}
+
+// diff compares two values of the same type and returns the changes between them, in traversal order. Structs,
+// slices, arrays, maps and non-nil pointers and interfaces are compared recursively. Elements only present in one of
+// two slices are recorded with an invalid value on the other side, just like map entries. All other values, e.g. a nil
+// and an empty slice, are compared as a whole. Unexported struct fields are ignored, funcs are equal only if they point
+// to the same code and NaN floats are equal to each other. Field names in paths are resolved by tags.
+func diff(a, b reflect.Value, tags tagResolver) []change {
+	d := &differ{tags: tags, visited: make(map[[2]uintptr]bool)}
+	d.compare(a, b, "")
+
+	return d.changes
+}
+
+// differ holds the state of a single diff.
+type differ struct {
+	changes []change
+	tags    tagResolver
+
+	// visited holds the pointer pairs that have already been compared and is used to break cycles.
+	visited map[[2]uintptr]bool
+}
+
+func (d *differ) compare(a, b reflect.Value, path string) {
+	//nolint:exhaustive // Composite kinds are compared recursively; other kinds are compared by value
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if field := a.Type().Field(i); field.IsExported() {
+				d.compare(a.Field(i), b.Field(i), joinPath(path, d.tags.fieldName(field)))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			d.record(a, b, path)
+			return
+		}
+
+		d.compareElems(a, b, path)
+	case reflect.Map:
+		d.compareMaps(a, b, path)
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.record(a, b, path)
+			}
+			return
+		}
+
+		pair := [2]uintptr{a.Pointer(), b.Pointer()}
+		if a.Pointer() == b.Pointer() || d.visited[pair] {
+			return
+		}
+		d.visited[pair] = true
+
+		d.compare(a.Elem(), b.Elem(), path)
+	case reflect.Interface:
+		if a.IsNil() && b.IsNil() {
+			return
+		}
+
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			d.record(a, b, path)
+			return
+		}
+
+		d.compare(a.Elem(), b.Elem(), path)
+	case reflect.Func:
+		if a.Pointer() != b.Pointer() {
+			d.record(a, b, path)
+		}
+	case reflect.Float32, reflect.Float64:
+		if !floatEqual(a.Float(), b.Float()) {
+			d.record(a, b, path)
+		}
+	case reflect.Complex64, reflect.Complex128:
+		ca, cb := a.Complex(), b.Complex()
+		if !floatEqual(real(ca), real(cb)) || !floatEqual(imag(ca), imag(cb)) {
+			d.record(a, b, path)
+		}
+	default:
+		if !a.Equal(b) {
+			d.record(a, b, path)
+		}
+	}
+}
+
+// compareElems compares the elements of two slices or arrays by index. Elements only present in one of them are
+// recorded as additions or removals.
+func (d *differ) compareElems(a, b reflect.Value, path string) {
+	for i := range min(a.Len(), b.Len()) {
+		d.compare(a.Index(i), b.Index(i), indexPath(path, i))
+	}
+
+	for i := b.Len(); i < a.Len(); i++ {
+		d.record(a.Index(i), reflect.Value{}, indexPath(path, i))
+	}
+
+	for i := a.Len(); i < b.Len(); i++ {
+		d.record(reflect.Value{}, b.Index(i), indexPath(path, i))
+	}
+}
+
+func (d *differ) compareMaps(a, b reflect.Value, path string) {
+	if a.IsNil() != b.IsNil() {
+		d.record(a, b, path)
+		return
+	}
+
+	for _, key := range sortedKeys(a) {
+		bv := b.MapIndex(key)
+		if !bv.IsValid() {
+			d.record(a.MapIndex(key), reflect.Value{}, keyPath(path, key))
+			continue
+		}
+
+		d.compare(a.MapIndex(key), bv, keyPath(path, key))
+	}
+
+	for _, key := range sortedKeys(b) {
+		if !a.MapIndex(key).IsValid() {
+			d.record(reflect.Value{}, b.MapIndex(key), keyPath(path, key))
+		}
+	}
+}
+
+// floatEqual reports whether x and y are equal, treating NaN as equal to NaN, so that an unchanged NaN isn't reported
+// as a change.
+func floatEqual(x, y float64) bool {
+	return x == y || (math.IsNaN(x) && math.IsNaN(y))
+}
+
+// sortedKeys returns the keys of the map sorted by their textual representation, to keep diffs deterministic.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	return keys
+}
+
+func (d *differ) record(a, b reflect.Value, path string) {
+	d.changes = append(d.changes, change{path: path, before: a, after: b})
+}
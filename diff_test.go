@@ -0,0 +1,89 @@
+//nolint:testpackage // We want to thoroughly test the underlying diff logic.
+package konfetty
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name   string
+		Next   *Node
+		Tags   []string
+		Labels map[string]int
+		Data   any
+		Hook   func()
+	}
+
+	hook := func() {}
+
+	a := &Node{
+		Name:   "a",
+		Tags:   []string{"x", "y"},
+		Labels: map[string]int{"keep": 1, "drop": 2},
+		Data:   1,
+		Hook:   hook,
+	}
+	a.Next = a
+
+	b := &Node{
+		Name:   "b",
+		Tags:   []string{"x", "z"},
+		Labels: map[string]int{"keep": 1, "add": 3},
+		Data:   "1",
+		Hook:   hook,
+	}
+	b.Next = b
+
+	changes := diff(reflect.ValueOf(a), reflect.ValueOf(b), tagResolver{})
+
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.path)
+	}
+
+	must.Eq(t, []string{"Name", "Tags[1]", "Labels[drop]", "Labels[add]", "Data"}, paths)
+	must.Eq[any](t, "a", changes[0].before.Interface())
+	must.Eq[any](t, "b", changes[0].after.Interface())
+	must.False(t, changes[3].before.IsValid())
+
+	must.SliceEmpty(t, diff(reflect.ValueOf(a), reflect.ValueOf(a), tagResolver{}))
+
+	// Slices of differing length are compared by index.
+	b.Tags = append(b.Tags, "w")
+	changes = diff(reflect.ValueOf(a.Tags), reflect.ValueOf(b.Tags), tagResolver{})
+	must.SliceLen(t, 2, changes)
+	must.Eq(t, "[2]", changes[1].path)
+	must.False(t, changes[1].before.IsValid())
+	must.Eq[any](t, "w", changes[1].after.Interface())
+}
+
+func TestDiffNaN(t *testing.T) {
+	t.Parallel()
+
+	type Metrics struct {
+		Ratio float64
+		Load  float32
+		Phase complex128
+	}
+
+	nan := math.NaN()
+
+	a := &Metrics{Ratio: nan, Load: float32(nan), Phase: complex(nan, 1)}
+	same := &Metrics{Ratio: nan, Load: float32(nan), Phase: complex(nan, 1)}
+	must.SliceEmpty(t, diff(reflect.ValueOf(a), reflect.ValueOf(same), tagResolver{}))
+
+	changed := &Metrics{Ratio: 1, Load: float32(nan), Phase: complex(nan, 2)}
+	changes := diff(reflect.ValueOf(a), reflect.ValueOf(changed), tagResolver{})
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.path)
+	}
+	must.Eq(t, []string{"Ratio", "Phase"}, paths)
+}
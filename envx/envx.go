@@ -0,0 +1,214 @@
+// Package envx provides a konfetty provider that loads the data-structure from environment variables.
+package envx
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nikoksr/konfetty"
+	"github.com/nikoksr/konfetty/internal/convert"
+)
+
+// Struct tag keys read by the provider.
+const (
+	envTagKey      = "env"
+	konfettyTagKey = "konfetty"
+)
+
+// Provider loads a T from environment variables. It implements konfetty.Provider.
+type Provider[T any] struct {
+	prefix    string
+	separator string
+	strict    bool
+}
+
+var _ konfetty.Provider[struct{}] = (*Provider[struct{}])(nil)
+
+// New returns a Provider that reads the environment variables starting with prefix. Every exported field of a
+// supported type, i.e. strings, bools, integers, floats, durations and types implementing encoding.TextUnmarshaler, is
+// read from the variable named after the uppercased field name. Fields of nested structs are prefixed with the name of
+// their parent, e.g. the field `Database.Port` is read from `APP_DATABASE_PORT` for the prefix `APP`. Fields of
+// embedded structs are promoted to the parent's level. The name can be overridden with an `env:"NAME"` tag or the
+// env option of the konfetty tag, e.g. `konfetty:"env=NAME"`, the env tag taking precedence. `env:"-"` and
+// `konfetty:"-"` skip a field. Since the pattern option of the konfetty tag consumes the rest of the tag, the env
+// option has to come before it. Recursive types are only followed until a struct type repeats on the current path.
+// Variables that aren't set leave their fields zero, so they are filled by the defaults.
+//
+//	processor := konfetty.FromProvider(envx.New[AppConfig]("APP"))
+func New[T any](prefix string) *Provider[T] {
+	return &Provider[T]{prefix: prefix, separator: "_"}
+}
+
+// WithSeparator sets the separator placed between the prefix and the names of nested fields, `_` by default.
+func (p *Provider[T]) WithSeparator(separator string) *Provider[T] {
+	p.separator = separator
+	return p
+}
+
+// WithStrict makes Load fail if variables starting with the prefix and separator are set that don't map to any field
+// of T, which usually hints at a typo. The error lists the unknown variables. Without a prefix, there's no way to tell
+// unrelated variables apart, so the check is skipped.
+func (p *Provider[T]) WithStrict() *Provider[T] {
+	p.strict = true
+	return p
+}
+
+// Load reads the config from the environment.
+func (p *Provider[T]) Load() (T, error) {
+	var cfg T
+
+	known := make(map[string]bool)
+	visiting := make(map[reflect.Type]bool)
+	if _, err := p.load(reflect.ValueOf(&cfg).Elem(), p.prefix, known, visiting); err != nil {
+		return cfg, err
+	}
+
+	if p.strict && p.prefix != "" {
+		if err := p.checkUnknown(known); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// checkUnknown returns an error listing the set variables starting with the prefix that aren't known.
+func (p *Provider[T]) checkUnknown(known map[string]bool) error {
+	var unknown []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, p.prefix+p.separator) && !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return fmt.Errorf("unknown environment variables: %s", strings.Join(unknown, ", "))
+}
+
+// load populates the struct v from the variables starting with prefix and reports whether any variable was set. The
+// names of all variables that map to a field are added to known. visiting holds the struct types on the current path;
+// a type already on it is not descended into again, which would never end for recursive types like linked lists.
+func (p *Provider[T]) load(
+	v reflect.Value,
+	prefix string,
+	known map[string]bool,
+	visiting map[reflect.Type]bool,
+) (bool, error) {
+	if v.Kind() != reflect.Struct || visiting[v.Type()] {
+		return false, nil
+	}
+
+	visiting[v.Type()] = true
+	defer delete(visiting, v.Type())
+
+	var found bool
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get(konfettyTagKey) == "-" {
+			continue
+		}
+
+		name := strings.ToUpper(field.Name)
+		if tag, ok := konfettyName(field); ok {
+			name = tag
+		}
+		if tag, ok := field.Tag.Lookup(envTagKey); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+
+		fieldPrefix := prefix
+		if !field.Anonymous {
+			fieldPrefix = p.join(prefix, name)
+		}
+
+		ok, err := p.loadField(v.Field(i), fieldPrefix, known, visiting)
+		if err != nil {
+			return false, err
+		}
+		found = found || ok
+	}
+
+	return found, nil
+}
+
+// loadField populates the field v from the variable name, or from the variables starting with name if v is a
+// struct, and reports whether any variable was set. Nil pointers are only allocated if a variable was set.
+func (p *Provider[T]) loadField(
+	v reflect.Value,
+	name string,
+	known map[string]bool,
+	visiting map[reflect.Type]bool,
+) (bool, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			elem := reflect.New(v.Type().Elem())
+			ok, err := p.loadField(elem.Elem(), name, known, visiting)
+			if ok {
+				v.Set(elem)
+			}
+
+			return ok, err
+		}
+
+		return p.loadField(v.Elem(), name, known, visiting)
+	}
+
+	if !convert.CanSetString(v.Type()) {
+		return p.load(v, name, known, visiting)
+	}
+
+	known[name] = true
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return false, nil
+	}
+
+	if err := convert.SetString(v, value); err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// konfettyName returns the value of the env option of the field's konfetty tag, e.g. `konfetty:"env=NAME"`. Options
+// after the pattern option belong to the pattern and are ignored.
+func konfettyName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get(konfettyTagKey)
+	for tag != "" {
+		var option string
+		option, tag, _ = strings.Cut(tag, ",")
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch key {
+		case "pattern":
+			return "", false
+		case envTagKey:
+			if value != "" {
+				return value, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (p *Provider[T]) join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + p.separator + name
+}
@@ -0,0 +1,115 @@
+package envx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+	"github.com/nikoksr/konfetty/envx"
+)
+
+type Common struct {
+	Debug bool
+}
+
+type DatabaseConfig struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
+type AppConfig struct {
+	Common
+	Database DatabaseConfig
+	Replica  *DatabaseConfig
+	Cache    *DatabaseConfig
+	Name     string  `env:"APP_NAME"`
+	Ratio    float64 `env:"-"`
+	Secret   string  `konfetty:"-"`
+}
+
+func TestProvider(t *testing.T) {
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_DATABASE_PORT", "6543")
+	t.Setenv("APP_DATABASE_TIMEOUT", "5s")
+	t.Setenv("APP_REPLICA_HOST", "replica")
+	t.Setenv("APP_APP_NAME", "konfetty")
+	t.Setenv("APP_RATIO", "0.5")
+	t.Setenv("APP_SECRET", "secret")
+
+	result, err := konfetty.FromProvider(envx.New[AppConfig]("APP")).
+		WithDefaults(DatabaseConfig{Host: "localhost", Port: 5432}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &AppConfig{
+		Common:   Common{Debug: true},
+		Database: DatabaseConfig{Host: "localhost", Port: 6543, Timeout: 5 * time.Second},
+		Replica:  &DatabaseConfig{Host: "replica", Port: 5432},
+		Name:     "konfetty",
+	}, result)
+}
+
+func TestProviderSeparator(t *testing.T) {
+	t.Setenv("DATABASE__HOST", "db")
+
+	result, err := envx.New[AppConfig]("").WithSeparator("__").Load()
+	must.NoError(t, err)
+	must.Eq(t, "db", result.Database.Host)
+}
+
+func TestProviderInvalidValue(t *testing.T) {
+	t.Setenv("APP_DATABASE_PORT", "not a number")
+
+	_, err := konfetty.FromProvider(envx.New[AppConfig]("APP")).Build()
+	must.ErrorContains(t, err, "load: from provider: APP_DATABASE_PORT")
+}
+
+func TestProviderStrict(t *testing.T) {
+	t.Setenv("STRICT_DATABASE_PORT", "6543")
+	t.Setenv("STRICT_DATABASE_HOTS", "db.internal")
+	t.Setenv("STRICT_APP_NAME", "konfetty")
+	t.Setenv("STRICTER_DEBUG", "true")
+
+	result, err := envx.New[AppConfig]("STRICT").Load()
+	must.NoError(t, err)
+	must.Eq(t, 6543, result.Database.Port)
+
+	_, err = konfetty.FromProvider(envx.New[AppConfig]("STRICT").WithStrict()).Build()
+	must.ErrorContains(t, err, "unknown environment variables: STRICT_DATABASE_HOTS")
+	must.StrNotContains(t, err.Error(), "STRICTER_DEBUG")
+	must.StrNotContains(t, err.Error(), "STRICT_APP_NAME")
+}
+
+func TestProviderKonfettyTag(t *testing.T) {
+	type Config struct {
+		Host  string `konfetty:"nodefault,env=SERVER_HOST"`
+		Port  int    `konfetty:"env=PORT_NUMBER" env:"PORT"`
+		Match string `konfetty:"pattern=^[a-z]+$,env=IGNORED"`
+	}
+
+	t.Setenv("APP_SERVER_HOST", "example.com")
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_PORT_NUMBER", "9090")
+	t.Setenv("APP_MATCH", "match")
+
+	result, err := envx.New[Config]("APP").Load()
+	must.NoError(t, err)
+	must.Eq(t, Config{Host: "example.com", Port: 8080, Match: "match"}, result)
+}
+
+func TestProviderRecursiveType(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	t.Setenv("APP_NAME", "head")
+	t.Setenv("APP_NEXT_NAME", "tail")
+
+	result, err := envx.New[node]("APP").Load()
+	must.NoError(t, err)
+	must.Eq(t, "head", result.Name)
+	must.Nil(t, result.Next)
+}
@@ -6,9 +6,71 @@ var (
 	// ErrCircularReference is returned when a circular reference is detected in the config structure.
 	ErrCircularReference = errors.New("circular reference detected")
 
+	// ErrNoDataSource is returned when a processor has no data source to load the config from, e.g. when FromStruct
+	// was called with a nil pointer.
+	ErrNoDataSource = errors.New("no data source provided")
+
 	// ErrNilConfig is returned when the config passed to applyDefaults is nil.
 	ErrNilConfig = errors.New("config cannot be nil")
 
+	// ErrInvalidTag is returned when a konfetty struct tag can't be parsed or doesn't fit the field it's attached to.
+	ErrInvalidTag = errors.New("invalid konfetty tag")
+
+	// ErrNotIdempotent is returned by processors verifying idempotence when applying the defaults twice changes the
+	// config.
+	ErrNotIdempotent = errors.New("defaulting is not idempotent")
+
+	// ErrPatternMismatch is returned when a string field doesn't match the pattern set in its konfetty tag.
+	ErrPatternMismatch = errors.New("pattern mismatch")
+
+	// ErrOutOfRange is returned by InRange validators when a field's value lies outside of the allowed range.
+	ErrOutOfRange = errors.New("out of range")
+
+	// ErrNotAllowed is returned by OneOf validators when a field's value isn't one of the allowed values.
+	ErrNotAllowed = errors.New("value not allowed")
+
+	// ErrConflictingDefaults is returned by processors checking for conflicts when multiple defaults provide different
+	// values for the same field.
+	ErrConflictingDefaults = errors.New("conflicting defaults")
+
+	// ErrInvalidDefault is returned when a value registered as default can't act as one, e.g. a plain int.
+	ErrInvalidDefault = errors.New("invalid default")
+
+	// ErrTypeMismatch is returned when a default value doesn't fit the type of the field it's applied to.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrDefaultParse is returned when a textual default, e.g. a string default for a field implementing
+	// encoding.TextUnmarshaler or the value of a default struct tag, can't be parsed into the field's type.
+	ErrDefaultParse = errors.New("can't parse default")
+
+	// ErrUnsupportedDefaultKind is returned when a default can't be merged into a field because of the field's kind,
+	// e.g. a `merge=add` option on a string field.
+	ErrUnsupportedDefaultKind = errors.New("unsupported default kind")
+
+	// ErrUnexportedDefault is returned in strict mode when defaults are registered for the type of an unexported field,
+	// which can't be set and would otherwise be skipped silently.
+	ErrUnexportedDefault = errors.New("default for unexported field")
+
+	// ErrUnreachableDefault is returned in strict mode when a default is registered for a type that doesn't occur in
+	// the config structure, e.g. because of a copy-paste mistake, so it can never be applied.
+	ErrUnreachableDefault = errors.New("default for type not in config")
+
+	// ErrMaxDepthExceeded is returned when the config structure is nested deeper than the limit set with WithMaxDepth.
+	ErrMaxDepthExceeded = errors.New("maximum depth exceeded")
+
+	// ErrInvalidPath is returned when a field path can't be parsed.
+	ErrInvalidPath = errors.New("invalid field path")
+
+	// ErrUnknownPath is returned when a field path doesn't lead to a field of the config structure.
+	ErrUnknownPath = errors.New("unknown field path")
+
+	// ErrPanic is returned by processors recovering panics when a user-supplied function panics during the build.
+	ErrPanic = errors.New("panic")
+
+	// ErrDuplicateFlag is returned by processors created with FromFlags when two fields resolve to the same flag name
+	// or a field's flag is already defined on the flag set.
+	ErrDuplicateFlag = errors.New("duplicate flag")
+
 	// ErrNotPointer is returned when the config passed to applyDefaults is not a pointer.
 	ErrNotPointer = errors.New("config must be a pointer to a struct")
 )
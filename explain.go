@@ -0,0 +1,143 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ExplainDefault returns a human-readable explanation of whether one of the given defaults would be applied to the
+// field at the given path of the loaded config, and why. It is a diagnostic tool for debugging defaults that don't
+// apply as expected and uses the same precedence rules as the defaulting pass: defaults of outer types win over
+// defaults of inner types, and later registrations win over earlier ones.
+//
+//	fmt.Println(konfetty.ExplainDefault(cfg, "Database.Port", DatabaseConfig{Port: 5432}))
+//	// Database.Port: would apply, the field is zero and would be set to 5432 by default #0 of type DatabaseConfig
+func ExplainDefault[T any](loaded *T, field string, defaults ...any) string {
+	if loaded == nil {
+		return field + ": can't explain, the config is nil"
+	}
+
+	segments, err := parsePath(field)
+	if err != nil {
+		return fmt.Sprintf("%s: can't explain, %v", field, err)
+	}
+
+	last := segments[len(segments)-1]
+	if last.isIndex {
+		return field + ": can't explain, the path must end in a struct field"
+	}
+
+	// Collect the values along the path; each of them may receive defaults that reach the field.
+	v := reflect.ValueOf(loaded).Elem()
+	ancestors := []reflect.Value{v}
+	for _, segment := range segments[:len(segments)-1] {
+		if v, err = step(v, segment); err != nil {
+			return fmt.Sprintf("%s: can't explain, %v", field, err)
+		}
+		ancestors = append(ancestors, v)
+	}
+
+	parent := indirect(v)
+	if !parent.IsValid() || parent.Kind() != reflect.Struct {
+		return field + ": can't explain, the parent of the field is nil or not a struct"
+	}
+
+	structField, ok := parent.Type().FieldByName(last.name)
+	if !ok {
+		return fmt.Sprintf("%s: no such field in %s", field, parent.Type())
+	}
+
+	if !structField.IsExported() {
+		return field + ": won't apply, the field is unexported and defaults are never applied to unexported fields"
+	}
+
+	if (tagResolver{}).skips(structField) {
+		return field + `: won't apply, the field is excluded from processing by its konfetty:"-" tag`
+	}
+
+	if opts, err := (tagResolver{}).parse(structField); err == nil && opts.noDefault {
+		return field + `: won't apply, the field is excluded from defaulting by its konfetty:"nodefault" tag`
+	}
+
+	fv, err := parent.FieldByIndexErr(structField.Index)
+	if err != nil {
+		return field + ": can't explain, the field is behind a nil embedded pointer"
+	}
+
+	winner, index, value, typeMatched := findWinningDefault(ancestors, segments, defaults)
+
+	switch {
+	case !fv.IsZero() && winner == nil:
+		return fmt.Sprintf("%s: already set to %v, and no matching default provides a value", field, fv)
+	case !fv.IsZero():
+		return fmt.Sprintf("%s: won't apply, the field is already set to %v and defaults only fill zero values "+
+			"(default #%d of type %s would provide %v)", field, fv, index, winner, value)
+	case winner == nil && !typeMatched:
+		return fmt.Sprintf("%s: no matching default, none of the given defaults has the type of the field's "+
+			"parent (%s) or one of its ancestors", field, parent.Type())
+	case winner == nil:
+		return field + ": no matching default, the defaults of matching types don't provide a value for the field"
+	default:
+		return fmt.Sprintf("%s: would apply, the field is zero and would be set to %v by default #%d of type %s",
+			field, value, index, winner)
+	}
+}
+
+// findWinningDefault finds the default that would supply the field at the end of the segments. Ancestors are checked
+// from the outermost to the innermost and defaults from the last to the first registered, which mirrors the order in
+// which the defaulting pass fills zero fields.
+func findWinningDefault(
+	ancestors []reflect.Value,
+	segments []pathSegment,
+	defaults []any,
+) (reflect.Type, int, reflect.Value, bool) {
+	typeMatched := false
+
+	for depth, ancestor := range ancestors {
+		rest := segments[depth:]
+		if containsIndex(rest) {
+			continue
+		}
+
+		ancestorType := derefType(ancestor.Type())
+		if ancestor.Kind() == reflect.Interface {
+			if elem := indirect(ancestor); elem.IsValid() {
+				ancestorType = elem.Type()
+			}
+		}
+
+		for i := len(defaults) - 1; i >= 0; i-- {
+			dv := reflect.ValueOf(defaults[i])
+			if !dv.IsValid() || derefType(dv.Type()) != ancestorType {
+				continue
+			}
+			typeMatched = true
+
+			src, err := followPath(dv, rest)
+			if err == nil && !src.IsZero() {
+				return dv.Type(), i, src, true
+			}
+		}
+	}
+
+	return nil, -1, reflect.Value{}, typeMatched
+}
+
+func containsIndex(segments []pathSegment) bool {
+	for _, segment := range segments {
+		if segment.isIndex {
+			return true
+		}
+	}
+
+	return false
+}
+
+// derefType strips all pointer indirections from t.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
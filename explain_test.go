@@ -0,0 +1,112 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type ExplainDatabase struct {
+	Host string
+	Port int
+}
+
+type ExplainConfig struct {
+	Name     string
+	Owner    string `konfetty:"nodefault"`
+	Database ExplainDatabase
+	secret   string //nolint:unused // Used for testing that unexported fields are explained
+}
+
+func TestExplainDefault(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   *ExplainConfig
+		field    string
+		defaults []any
+		contains string
+	}{
+		{
+			name:     "Would apply",
+			config:   &ExplainConfig{},
+			field:    "Database.Port",
+			defaults: []any{ExplainDatabase{Port: 5432}},
+			contains: "would apply, the field is zero and would be set to 5432 by default #0",
+		},
+		{
+			name:     "Later default wins",
+			config:   &ExplainConfig{},
+			field:    "Database.Port",
+			defaults: []any{ExplainDatabase{Port: 5432}, ExplainDatabase{Port: 3306}},
+			contains: "would be set to 3306 by default #1",
+		},
+		{
+			name:     "Outer default wins",
+			config:   &ExplainConfig{},
+			field:    "Database.Port",
+			defaults: []any{ExplainDatabase{Port: 5432}, &ExplainConfig{Database: ExplainDatabase{Port: 1}}},
+			contains: "would be set to 1 by default #1 of type *konfetty_test.ExplainConfig",
+		},
+		{
+			name:     "No default",
+			config:   &ExplainConfig{},
+			field:    "Owner",
+			defaults: []any{ExplainConfig{Owner: "admin"}},
+			contains: `won't apply, the field is excluded from defaulting by its konfetty:"nodefault" tag`,
+		},
+		{
+			name:     "Already set",
+			config:   &ExplainConfig{Database: ExplainDatabase{Port: 8080}},
+			field:    "Database.Port",
+			defaults: []any{ExplainDatabase{Port: 5432}},
+			contains: "won't apply, the field is already set to 8080",
+		},
+		{
+			name:     "No default of matching type",
+			config:   &ExplainConfig{},
+			field:    "Database.Port",
+			defaults: []any{TestConfig{Age: 1}},
+			contains: "no matching default, none of the given defaults has the type",
+		},
+		{
+			name:     "No value in matching default",
+			config:   &ExplainConfig{},
+			field:    "Database.Port",
+			defaults: []any{ExplainDatabase{Host: "localhost"}},
+			contains: "no matching default, the defaults of matching types don't provide a value",
+		},
+		{
+			name:     "Unexported field",
+			config:   &ExplainConfig{},
+			field:    "secret",
+			defaults: []any{ExplainConfig{}},
+			contains: "won't apply, the field is unexported",
+		},
+		{
+			name:     "Unknown field",
+			config:   &ExplainConfig{},
+			field:    "Database.User",
+			contains: "no such field",
+		},
+		{
+			name:     "Invalid path",
+			config:   &ExplainConfig{},
+			field:    "Database..Port",
+			contains: "can't explain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			explanation := konfetty.ExplainDefault(tt.config, tt.field, tt.defaults...)
+			must.StrContains(t, explanation, tt.field)
+			must.StrContains(t, explanation, tt.contains)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package konfetty
+
+import "reflect"
+
+// WithFallback sets a complete data-structure, e.g. the previous or a global config, whose values fill the zero values
+// of the loaded data-structure across the whole tree. Unlike WithDefaults, which applies defaults to every value of
+// their type, the fallback is merged into the loaded data-structure as a whole, field by field: structs, maps and
+// pointers to structs are merged recursively, all other values, including slices, are only filled if they are unset,
+// which honors WithZeroFunc and WithEmptyAsUnset like the defaults. Fields tagged with `konfetty:"-"` aren't filled.
+//
+// The fallback is applied at the start of the defaults stage, so its values take precedence over all defaults. It's
+// read by every build, but never modified; values taken from it are copied. Unlike defaults, the values it fills aren't
+// listed in reports.
+//
+//	processor.WithFallback(previousConfig)
+func (p *Processor[T]) WithFallback(fallback *T) *Processor[T] {
+	p.builder.fallback = fallback
+	return p
+}
+
+// WithBase sets a complete data-structure as the lowest layer of the data source. It's merged under the loaded
+// data-structure right after loading, like WithFallback: the loaded values take precedence and only zero values are
+// filled from the base. The defaults are applied afterward, so they only fill what both leave zero. Layers from lowest
+// to highest precedence:
+//
+//  1. defaults added with WithDefaults and friends,
+//  2. the base,
+//  3. the loaded data-structure.
+//
+// Unlike the fallback, the base is part of the loaded data-structure, so transformers and validators comparing the
+// final data-structure to the loaded one see its values, too. It's never modified; values taken from it are copied.
+//
+//	processor := konfetty.FromProvider(fileProvider).WithBase(&baseConfig)
+func (p *Processor[T]) WithBase(base *T) *Processor[T] {
+	p.builder.base = base
+	return p
+}
+
+// applyBase fills the zero values of the loaded cfg from the base, if there is one.
+func (b *Builder[T]) applyBase(cfg *T) {
+	if b.base == nil {
+		return
+	}
+
+	underlay(reflect.ValueOf(cfg).Elem(), reflect.ValueOf(b.base).Elem(), b.unset(), b.tags())
+}
+
+// applyFallback fills the zero values of cfg from the fallback, if there is one.
+func (b *Builder[T]) applyFallback(cfg *T) {
+	if b.fallback == nil {
+		return
+	}
+
+	underlay(reflect.ValueOf(cfg).Elem(), reflect.ValueOf(b.fallback).Elem(), b.unset(), b.tags())
+}
+
+// unset returns the func deciding whether a value is unset and gets filled, which honors the zero funcs and
+// WithEmptyAsUnset just like the defaults.
+func (b *Builder[T]) unset() func(reflect.Value) bool {
+	d := &defaulter{zeroFuncs: b.zeroFuncs, emptyAsUnset: b.emptyAsUnset}
+	return d.isZero
+}
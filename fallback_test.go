@@ -0,0 +1,207 @@
+package konfetty_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithFallback(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string
+		Port int
+	}
+
+	type Device struct {
+		Name  string
+		Power int
+	}
+
+	type Config struct {
+		Name     string
+		Database *Database
+		Backup   *Database
+		Devices  map[string]Device
+		Tags     []string
+		Hosts    []string
+	}
+
+	fallback := &Config{
+		Name:     "previous",
+		Database: &Database{Host: "db.internal", Port: 5432},
+		Backup:   &Database{Host: "backup.internal", Port: 5433},
+		Devices:  map[string]Device{"lamp": {Name: "lamp", Power: 10}, "fan": {Name: "fan", Power: 20}},
+		Tags:     []string{"a", "b"},
+		Hosts:    []string{"fallback"},
+	}
+
+	config := &Config{
+		Database: &Database{Port: 6543},
+		Devices:  map[string]Device{"lamp": {Power: 15}},
+		Hosts:    []string{"loaded"},
+	}
+
+	result, err := konfetty.FromStruct(config).
+		WithFallback(fallback).
+		WithDefaults(Database{Host: "localhost", Port: 1}, Device{Name: "device", Power: 1}).
+		Build()
+	must.NoError(t, err)
+
+	must.Eq(t, "previous", result.Name)
+	must.Eq(t, Database{Host: "db.internal", Port: 6543}, *result.Database)
+	must.Eq(t, Database{Host: "backup.internal", Port: 5433}, *result.Backup)
+	must.Eq(t, map[string]Device{"lamp": {Name: "lamp", Power: 15}, "fan": {Name: "fan", Power: 20}}, result.Devices)
+	must.Eq(t, []string{"a", "b"}, result.Tags)
+	must.Eq(t, []string{"loaded"}, result.Hosts)
+
+	// Values taken from the fallback are copies.
+	result.Backup.Port = 1
+	result.Tags[0] = "changed"
+	must.Eq(t, 5433, fallback.Backup.Port)
+	must.Eq(t, "a", fallback.Tags[0])
+	must.MapLen(t, 2, fallback.Devices)
+
+	// Without a fallback, the defaults fill the zero values.
+	result, err = konfetty.FromStruct(&Config{Database: &Database{}}).
+		WithFallback(nil).
+		WithDefaults(Database{Host: "localhost", Port: 1}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, Database{Host: "localhost", Port: 1}, *result.Database)
+}
+
+func TestWithFallbackUnset(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name    string
+		Retries int
+		Tags    []string
+		Next    *Node `konfetty:"weakref"`
+	}
+
+	// Both the loaded node and the fallback point back to themselves, which mustn't be followed forever.
+	fallback := &Node{Name: "fallback", Retries: 3, Tags: []string{"fallback"}}
+	fallback.Next = fallback
+
+	config := &Node{Retries: -1, Tags: []string{}}
+	config.Next = config
+
+	result, err := konfetty.FromStruct(config).
+		WithFallback(fallback).
+		WithZeroFunc(reflect.TypeFor[int](), func(v reflect.Value) bool { return v.Int() < 0 }).
+		WithEmptyAsUnset().
+		Build()
+	must.NoError(t, err)
+
+	// Values are unset according to the zero funcs and WithEmptyAsUnset, like for the defaults.
+	must.Eq(t, "fallback", result.Name)
+	must.Eq(t, 3, result.Retries)
+	must.Eq(t, []string{"fallback"}, result.Tags)
+	must.True(t, result.Next == config)
+}
+
+func TestWithBase(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host    string
+		Port    int
+		Timeout int
+	}
+
+	type Config struct {
+		Name     string
+		Database Database
+		Tags     []string
+	}
+
+	provider := StaticProvider[Config]{config: Config{Database: Database{Host: "db.internal"}}}
+	base := &Config{Name: "base", Database: Database{Host: "localhost", Port: 5432}}
+
+	var loaded Config
+	cfg, err := konfetty.FromProvider[Config](provider).
+		WithBase(base).
+		WithDefaults(Config{Name: "default", Tags: []string{"default"}}, Database{Port: 3306, Timeout: 30}).
+		WithValidatorDiff(func(original, _ *Config) error {
+			loaded = *original
+			return nil
+		}).
+		Build()
+	must.NoError(t, err)
+
+	// Precedence: defaults < base < provider.
+	must.Eq(t, &Config{
+		Name:     "base",
+		Database: Database{Host: "db.internal", Port: 5432, Timeout: 30},
+		Tags:     []string{"default"},
+	}, cfg)
+
+	// The base is part of the loaded data-structure.
+	must.Eq(t, Config{Name: "base", Database: Database{Host: "db.internal", Port: 5432}}, loaded)
+
+	// The base is never modified.
+	must.Eq(t, &Config{Name: "base", Database: Database{Host: "localhost", Port: 5432}}, base)
+}
+
+func TestWithBaseDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string
+		Port int
+	}
+
+	type Config struct {
+		M map[string]string
+		P *Inner
+	}
+
+	source := &Config{M: map[string]string{"source": "value"}, P: &Inner{Port: 8080}}
+	base := &Config{M: map[string]string{"base": "value"}, P: &Inner{Name: "base"}}
+
+	cfg, err := konfetty.FromStruct(source).
+		WithBase(base).
+		WithDeepCopy().
+		Build()
+	must.NoError(t, err)
+
+	must.Eq(t, map[string]string{"source": "value", "base": "value"}, cfg.M)
+	must.Eq(t, &Inner{Name: "base", Port: 8080}, cfg.P)
+
+	// In deep-copy mode, the base is applied to a copy of the source.
+	must.Eq(t, map[string]string{"source": "value"}, source.M)
+	must.Eq(t, "", source.P.Name)
+}
+
+func TestWithFallbackSkippedFields(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Host  string
+		Token string `konfetty:"-"`
+	}
+
+	type Config struct {
+		Name   string
+		Secret string `konfetty:"-"`
+		Server Server
+	}
+
+	layer := &Config{Name: "layer", Secret: "layer", Server: Server{Host: "layer", Token: "layer"}}
+	want := &Config{Name: "layer", Server: Server{Host: "loaded"}}
+
+	// Fields tagged with `konfetty:"-"` are neither filled from the fallback nor from the base.
+	result, err := konfetty.FromStruct(&Config{Server: Server{Host: "loaded"}}).WithFallback(layer).Build()
+	must.NoError(t, err)
+	must.Eq(t, want, result)
+
+	result, err = konfetty.FromStruct(&Config{Server: Server{Host: "loaded"}}).WithBase(layer).Build()
+	must.NoError(t, err)
+	must.Eq(t, want, result)
+}
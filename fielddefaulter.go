@@ -0,0 +1,69 @@
+package konfetty
+
+import "reflect"
+
+// FieldDefaulter is implemented by types that supply the defaults of their own fields, e.g. because the defaults are
+// data-driven and looked up from a table. During the defaulting pass, DefaultFor is called with the Go name of every
+// exported field of the implementing struct that is still unset after the defaults registered for the struct's type
+// and its computed defaults were applied. If it returns true, the returned value is assigned to the field; string
+// values are parsed into fields implementing encoding.TextUnmarshaler. Returning false leaves the field to the defaults
+// of its own type.
+//
+//	func (d *Device) DefaultFor(field string) (any, bool) {
+//		value, ok := deviceDefaults[d.Model][field]
+//		return value, ok
+//	}
+type FieldDefaulter interface {
+	DefaultFor(field string) (any, bool)
+}
+
+//nolint:gochecknoglobals // Immutable type descriptor
+var fieldDefaulterType = reflect.TypeFor[FieldDefaulter]()
+
+// fieldDefaulter returns v as a FieldDefaulter, if it implements the interface.
+func fieldDefaulter(v reflect.Value) FieldDefaulter {
+	if !v.CanInterface() {
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(fieldDefaulterType) {
+		//nolint:errcheck,forcetypeassert // The type implements FieldDefaulter
+		return v.Addr().Interface().(FieldDefaulter)
+	}
+
+	if v.Type().Implements(fieldDefaulterType) {
+		//nolint:errcheck,forcetypeassert // The type implements FieldDefaulter
+		return v.Interface().(FieldDefaulter)
+	}
+
+	return nil
+}
+
+// applyFieldDefaulter assigns the default fd returns for the field to v, if v is unset.
+func (d *defaulter) applyFieldDefaulter(
+	fd FieldDefaulter,
+	v reflect.Value,
+	field reflect.StructField,
+	path string,
+) error {
+	if !v.CanSet() || !d.isZero(v) {
+		return nil
+	}
+
+	value, ok := fd.DefaultFor(field.Name)
+	if !ok || value == nil {
+		return nil
+	}
+
+	before := reflect.New(v.Type()).Elem()
+	before.Set(v)
+
+	if err := setField(v, reflect.ValueOf(value)); err != nil {
+		return wrapPath(path, err)
+	}
+
+	d.origin = registeredDefault{index: -1, defaulter: reflect.TypeOf(fd)}
+	d.record(path, before, v)
+
+	return nil
+}
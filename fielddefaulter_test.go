@@ -0,0 +1,86 @@
+package konfetty_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+// modelDefaults holds the defaults of the table-driven devices, keyed by model and field.
+//
+//nolint:gochecknoglobals // Test fixture
+var modelDefaults = map[string]map[string]any{
+	"bulb": {"Brightness": 80, "Timeout": "30s"},
+	"fan":  {"Speed": 3},
+}
+
+type TableDevice struct {
+	Model      string
+	Name       string
+	Brightness int
+	Speed      int
+	Timeout    time.Duration
+}
+
+func (d *TableDevice) DefaultFor(field string) (any, bool) {
+	if field == "Timeout" {
+		// Durations aren't text-unmarshalable, so the string is handed back as a duration.
+		if s, ok := modelDefaults[d.Model][field].(string); ok {
+			timeout, err := time.ParseDuration(s)
+			return timeout, err == nil
+		}
+	}
+
+	value, ok := modelDefaults[d.Model][field]
+
+	return value, ok
+}
+
+// MismatchDevice returns a default that doesn't fit its field.
+type MismatchDevice struct {
+	Port int
+}
+
+func (MismatchDevice) DefaultFor(string) (any, bool) {
+	return "8080", true
+}
+
+func TestFieldDefaulter(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Devices []TableDevice
+		Single  *TableDevice
+	}
+
+	config := &Config{
+		Devices: []TableDevice{{Model: "bulb"}, {Model: "fan", Speed: 1}, {Model: "unknown"}},
+		Single:  &TableDevice{Model: "bulb", Brightness: 20},
+	}
+
+	result, report, err := konfetty.FromStruct(config).
+		WithDefaults(TableDevice{Name: "device", Brightness: 10}).
+		BuildWithReport()
+	must.NoError(t, err)
+
+	// Registered defaults apply first; DefaultFor fills the fields they left unset and declines the others.
+	must.Eq(t, []TableDevice{
+		{Model: "bulb", Name: "device", Brightness: 10, Timeout: 30 * time.Second},
+		{Model: "fan", Name: "device", Brightness: 10, Speed: 1},
+		{Model: "unknown", Name: "device", Brightness: 10},
+	}, result.Devices)
+	must.Eq(t, TableDevice{Model: "bulb", Name: "device", Brightness: 20, Timeout: 30 * time.Second}, *result.Single)
+
+	var changes []string
+	for _, c := range report.Changes {
+		changes = append(changes, c.String())
+	}
+	must.SliceContains(t, changes, "Devices[0].Timeout: 0s -> 30s (field defaulter *konfetty_test.TableDevice)")
+
+	_, err = konfetty.FromStruct(&MismatchDevice{}).Build()
+	must.ErrorIs(t, err, konfetty.ErrTypeMismatch)
+	must.ErrorContains(t, err, "Port")
+}
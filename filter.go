@@ -0,0 +1,91 @@
+package konfetty
+
+import (
+	"reflect"
+)
+
+// FilterType removes the elements of type U for which keep returns false from all slices reachable from root, which
+// must be a pointer. Slices of U, *U and interfaces are filtered; in interface slices, only the elements holding a U
+// or *U are passed to keep, all others are kept. The remaining elements are compacted in place, preserving their
+// order. FilterType is meant to be used in transformers:
+//
+//	processor.WithTransformer(func(cfg *Config) {
+//		konfetty.FilterType(cfg, func(light *LightDevice) bool { return light.Enabled })
+//	})
+func FilterType[U any](root any, keep func(*U) bool) {
+	v := reflect.ValueOf(root)
+	if v.Kind() != reflect.Ptr || v.IsNil() || keep == nil {
+		return
+	}
+
+	target := reflect.TypeFor[U]()
+
+	//nolint:errcheck // The visit func never fails
+	traverse(v, tagResolver{}, func(v reflect.Value, _ string) error {
+		if v.Kind() == reflect.Slice && v.CanSet() {
+			filterSlice(v, target, func(elem reflect.Value) bool {
+				//nolint:forcetypeassert // filterSlice only passes pointers to U
+				return keep(elem.Interface().(*U))
+			})
+		}
+
+		return nil
+	})
+}
+
+// filterSlice compacts the slice v in place, dropping the elements of the target type that keep rejects. keep receives
+// a pointer to the element.
+func filterSlice(v reflect.Value, target reflect.Type, keep func(reflect.Value) bool) {
+	n := 0
+	for i := range v.Len() {
+		elem := v.Index(i)
+		if !keepElement(elem, target, keep) {
+			continue
+		}
+
+		if n != i {
+			v.Index(n).Set(elem)
+		}
+		n++
+	}
+
+	if n == v.Len() {
+		return
+	}
+
+	// Clear the tail so the dropped elements can be garbage collected.
+	for i := n; i < v.Len(); i++ {
+		v.Index(i).SetZero()
+	}
+	v.SetLen(n)
+}
+
+func keepElement(elem reflect.Value, target reflect.Type, keep func(reflect.Value) bool) bool {
+	if elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			return true
+		}
+
+		if elem.Elem().Type() == target {
+			// Values stored in interfaces aren't addressable, so keep receives a pointer to a copy, which is written
+			// back to preserve changes made by keep.
+			elemCopy := reflect.New(target)
+			elemCopy.Elem().Set(elem.Elem())
+			kept := keep(elemCopy)
+			elem.Set(elemCopy.Elem())
+
+			return kept
+		}
+
+		elem = elem.Elem()
+	}
+
+	switch {
+	case elem.Type() == target:
+		return keep(elem.Addr())
+	case elem.Type() == reflect.PointerTo(target):
+		return elem.IsNil() || keep(elem)
+	default:
+		return true
+	}
+}
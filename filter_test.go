@@ -0,0 +1,78 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestFilterType(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name    string
+		Enabled bool
+	}
+
+	type Sensor struct {
+		Name string
+	}
+
+	type Room struct {
+		Devices []any
+		Lights  []*Device
+	}
+
+	type Config struct {
+		Rooms   []Room
+		Spares  map[string][]Device
+		Primary []Device
+	}
+
+	config := &Config{
+		Rooms: []Room{
+			{
+				Devices: []any{
+					Device{Name: "lamp", Enabled: true},
+					&Device{Name: "fan"},
+					Sensor{Name: "motion"},
+					nil,
+					&Device{Name: "heater", Enabled: true},
+				},
+				Lights: []*Device{{Name: "ceiling"}, nil, {Name: "desk", Enabled: true}},
+			},
+		},
+		Spares:  map[string][]Device{"garage": {{Name: "old"}, {Name: "new", Enabled: true}}},
+		Primary: []Device{{Name: "hub", Enabled: true}, {Name: "bridge"}},
+	}
+	primary := config.Primary
+
+	seen := 0
+	result, err := konfetty.FromStruct(config).
+		WithTransformer(func(cfg *Config) {
+			konfetty.FilterType(cfg, func(d *Device) bool {
+				seen++
+				d.Name += "!"
+
+				return d.Enabled
+			})
+		}).
+		Build()
+
+	must.NoError(t, err)
+	must.Eq(t, 9, seen)
+	must.Eq(t, []any{
+		Device{Name: "lamp!", Enabled: true},
+		Sensor{Name: "motion"},
+		nil,
+		&Device{Name: "heater!", Enabled: true},
+	}, result.Rooms[0].Devices)
+	must.Eq(t, []*Device{nil, {Name: "desk!", Enabled: true}}, result.Rooms[0].Lights)
+	must.Eq(t, map[string][]Device{"garage": {{Name: "new!", Enabled: true}}}, result.Spares)
+	must.Eq(t, []Device{{Name: "hub!", Enabled: true}}, result.Primary)
+
+	// Slices are compacted in place and the dropped elements are cleared.
+	must.Eq(t, []Device{{Name: "hub!", Enabled: true}, {}}, primary)
+}
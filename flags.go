@@ -0,0 +1,112 @@
+package konfetty
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/nikoksr/konfetty/internal/convert"
+)
+
+// Struct tag keys for customizing the flag registered for a field.
+const (
+	flagTagKey  = "flag"
+	usageTagKey = "usage"
+)
+
+// FromFlags initializes a Processor that loads the data-structure from command-line flags. A flag is registered on fs
+// for every exported field of a supported type: strings, bools, integers, floats, durations and types implementing
+// encoding.TextUnmarshaler. Flags are named after the lowercased field name and fields of nested structs are prefixed
+// with the name of their parent, e.g. `-database.port`. Fields of embedded structs are promoted to the parent's level.
+// The name can be overridden with a `flag:"name"` tag, while `flag:"-"` skips a field. The flag's help text is read
+// from the `usage` tag.
+//
+// The args, typically os.Args[1:], are parsed when the processor is built. Flags that aren't set leave their fields
+// zero, so they are filled by the defaults. If two fields resolve to the same flag name, or a flag of that name is
+// already defined on fs, Build returns ErrDuplicateFlag.
+//
+//	processor := konfetty.FromFlags[MyConfig](flag.CommandLine, os.Args[1:])
+func FromFlags[T any](fs *flag.FlagSet, args []string) *Processor[T] {
+	cfg := new(T)
+	bindErr := bindFlags(fs, reflect.ValueOf(cfg).Elem(), "")
+
+	p := FromLoaderFunc(func() (T, error) {
+		if err := fs.Parse(args); err != nil {
+			var zero T
+			return zero, fmt.Errorf("parse flags: %w", err)
+		}
+
+		return *cfg, nil
+	})
+
+	if bindErr != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("bind flags: %w", bindErr))
+	}
+
+	return p
+}
+
+// bindFlags registers a flag for every supported field of the struct v, recursing into nested structs. Registering
+// a name twice makes the flag package panic, so duplicate names are returned as an error instead.
+func bindFlags(fs *flag.FlagSet, v reflect.Value, prefix string) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.ToLower(field.Name)
+		if tag, ok := field.Tag.Lookup(flagTagKey); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+
+		fv := v.Field(i)
+		switch {
+		case convert.CanSetString(fv.Type()):
+			if fs.Lookup(prefix+name) != nil {
+				return fmt.Errorf("%w: -%s of field %s", ErrDuplicateFlag, prefix+name, field.Name)
+			}
+			fs.Var(flagValue{v: fv}, prefix+name, field.Tag.Get(usageTagKey))
+		case field.Anonymous && fv.Kind() == reflect.Struct:
+			if err := bindFlags(fs, fv, prefix); err != nil {
+				return err
+			}
+		case fv.Kind() == reflect.Struct:
+			if err := bindFlags(fs, fv, prefix+name+"."); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// flagValue adapts a struct field to the flag.Value interface.
+type flagValue struct {
+	v reflect.Value
+}
+
+func (f flagValue) String() string {
+	if !f.v.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprint(f.v.Interface())
+}
+
+func (f flagValue) Set(s string) error {
+	return convert.SetString(f.v, s)
+}
+
+// IsBoolFlag allows boolean flags to be set without a value, e.g. `-verbose`.
+func (f flagValue) IsBoolFlag() bool {
+	return f.v.IsValid() && f.v.Kind() == reflect.Bool
+}
@@ -0,0 +1,137 @@
+package konfetty_test
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestFromFlags(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		Verbose bool
+	}
+
+	type Database struct {
+		Host    string
+		Port    int `flag:"db-port" usage:"database port"`
+		Timeout time.Duration
+	}
+
+	type Config struct {
+		Base
+		Name     string
+		Ratio    float64
+		Secret   string `flag:"-"`
+		Database Database
+		Tags     []string
+	}
+
+	newFlagSet := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+
+		return fs
+	}
+
+	t.Run("Nested", func(t *testing.T) {
+		t.Parallel()
+
+		fs := newFlagSet()
+		args := []string{"-verbose", "-name=app", "-ratio", "0.5", "-database.db-port", "6543", "-database.timeout=3s"}
+
+		result, err := konfetty.FromFlags[Config](fs, args).
+			WithDefaults(Database{Host: "localhost", Port: 5432}).
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, &Config{
+			Base:     Base{Verbose: true},
+			Name:     "app",
+			Ratio:    0.5,
+			Database: Database{Host: "localhost", Port: 6543, Timeout: 3 * time.Second},
+		}, result)
+
+		must.Nil(t, fs.Lookup("secret"))
+		must.Nil(t, fs.Lookup("tags"))
+		must.Eq(t, "database port", fs.Lookup("database.db-port").Usage)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromFlags[Config](newFlagSet(), []string{"-database.db-port", "abc"}).Build()
+		must.ErrorContains(t, err, "parse flags")
+	})
+
+	t.Run("UnknownFlag", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromFlags[Config](newFlagSet(), []string{"-unknown"}).Build()
+		must.ErrorContains(t, err, "flag provided but not defined")
+	})
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		t.Parallel()
+
+		type Duplicated struct {
+			Base
+			Debug bool `flag:"verbose"`
+		}
+
+		_, err := konfetty.FromFlags[Duplicated](newFlagSet(), nil).Build()
+		must.ErrorIs(t, err, konfetty.ErrDuplicateFlag)
+		must.ErrorContains(t, err, "-verbose of field Debug")
+
+		fs := newFlagSet()
+		fs.String("name", "", "defined elsewhere")
+
+		_, err = konfetty.FromFlags[Config](fs, nil).Build()
+		must.ErrorIs(t, err, konfetty.ErrDuplicateFlag)
+	})
+
+	t.Run("TextUnmarshaler", func(t *testing.T) {
+		t.Parallel()
+
+		type Logging struct {
+			Level LogLevel
+			Since time.Time
+		}
+
+		result, err := konfetty.FromFlags[Logging](newFlagSet(), []string{"-level=debug", "-since=2024-01-02T03:04:05Z"}).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, LevelDebug, result.Level)
+		must.Eq(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), result.Since)
+
+		_, err = konfetty.FromFlags[Logging](newFlagSet(), []string{"-level=trace"}).Build()
+		must.ErrorContains(t, err, `unknown log level "trace"`)
+	})
+}
+
+type LogLevel int
+
+const (
+	LevelInfo LogLevel = iota
+	LevelDebug
+)
+
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*l = LevelInfo
+	case "debug":
+		*l = LevelDebug
+	default:
+		return fmt.Errorf("unknown log level %q", text)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package konfetty
+
+import (
+	"reflect"
+	"sync"
+)
+
+// globalDefaults holds the defaults registered with RegisterGlobalDefault.
+//
+//nolint:gochecknoglobals // Global defaults are shared by all processors by design
+var globalDefaults struct {
+	mu     sync.RWMutex
+	values []any
+}
+
+// RegisterGlobalDefault registers a default that every processor includes, unless it opts out with
+// WithoutGlobalDefaults. This avoids repeating the same base defaults for every processor of an application with
+// multiple configs. It's safe for concurrent use and typically called during initialization; processors include the
+// global defaults registered at the time they are built. Values that can't act as defaults are rejected with
+// ErrInvalidDefault, see WithDefaults.
+//
+// Global defaults have a lower precedence than the defaults registered with a processor. They come first among the
+// defaults of their type, so per-processor defaults of the same type win, and OverrideDefaults replaces them.
+//
+//	func init() {
+//		konfetty.RegisterGlobalDefault(BaseDevice{Location: "Unknown"})
+//	}
+func RegisterGlobalDefault(defaultValue any) error {
+	if err := checkDefaultType(reflect.TypeOf(defaultValue)); err != nil {
+		return err
+	}
+
+	globalDefaults.mu.Lock()
+	defer globalDefaults.mu.Unlock()
+
+	globalDefaults.values = append(globalDefaults.values, defaultValue)
+
+	return nil
+}
+
+// WithoutGlobalDefaults excludes the defaults registered with RegisterGlobalDefault from the processor.
+func (p *Processor[T]) WithoutGlobalDefaults() *Processor[T] {
+	p.builder.noGlobalDefaults = true
+	return p
+}
+
+// registeredDefaults returns the defaults that apply to the processed data-structure: the global defaults, followed by
+// the defaults registered with the processor and the ones of its active profile.
+func (b *Builder[T]) registeredDefaults() map[reflect.Type][]any {
+	defaults := b.profiles.merge(b.defaults)
+	if b.noGlobalDefaults {
+		return defaults
+	}
+
+	globalDefaults.mu.RLock()
+	globals := globalDefaults.values
+	globalDefaults.mu.RUnlock()
+
+	if len(globals) == 0 {
+		return defaults
+	}
+
+	merged := make(map[reflect.Type][]any, len(defaults)+len(globals))
+	for _, dv := range globals {
+		if t := reflect.TypeOf(dv); !b.overridden[t] {
+			merged[t] = append(merged[t], dv)
+		}
+	}
+
+	for t, values := range defaults {
+		merged[t] = append(merged[t], values...)
+	}
+
+	return merged
+}
@@ -0,0 +1,95 @@
+package konfetty_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+// The global defaults are shared by all tests, so this test uses types no other test registers defaults for.
+func TestRegisterGlobalDefault(t *testing.T) {
+	t.Parallel()
+
+	type GlobalDevice struct {
+		Name     string
+		Location string
+	}
+
+	type GlobalConfig struct {
+		Devices []GlobalDevice
+	}
+
+	must.NoError(t, konfetty.RegisterGlobalDefault(GlobalDevice{Name: "device", Location: "Unknown"}))
+	must.ErrorIs(t, konfetty.RegisterGlobalDefault(42), konfetty.ErrInvalidDefault)
+
+	newConfig := func() *GlobalConfig {
+		return &GlobalConfig{Devices: []GlobalDevice{{}, {Name: "lamp"}}}
+	}
+
+	t.Run("Included", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(newConfig()).Build()
+		must.NoError(t, err)
+		must.Eq(t, []GlobalDevice{{Name: "device", Location: "Unknown"}, {Name: "lamp", Location: "Unknown"}},
+			result.Devices)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		t.Parallel()
+
+		// Per-processor defaults win, while the global default still fills the fields they leave zero.
+		result, err := konfetty.FromStruct(newConfig()).
+			WithDefaults(GlobalDevice{Location: "Kitchen"}).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, []GlobalDevice{{Name: "device", Location: "Kitchen"}, {Name: "lamp", Location: "Kitchen"}},
+			result.Devices)
+
+		result, err = konfetty.FromStruct(newConfig()).
+			OverrideDefaults(&GlobalDevice{Location: "Hall"}).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, []GlobalDevice{{Location: "Hall"}, {Name: "lamp", Location: "Hall"}}, result.Devices)
+	})
+
+	t.Run("Excluded", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(newConfig()).WithoutGlobalDefaults().Build()
+		must.NoError(t, err)
+		must.Eq(t, []GlobalDevice{{}, {Name: "lamp"}}, result.Devices)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		type GlobalSensor struct {
+			Unit string
+		}
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+				must.NoError(t, konfetty.RegisterGlobalDefault(GlobalSensor{Unit: "celsius"}))
+			}()
+
+			go func() {
+				defer wg.Done()
+				_, err := konfetty.FromStruct(newConfig()).Build()
+				must.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		result, err := konfetty.FromStruct(&GlobalSensor{}).Build()
+		must.NoError(t, err)
+		must.Eq(t, "celsius", result.Unit)
+	})
+}
@@ -0,0 +1,95 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// interfaceDefault is a default that applies to every struct type implementing iface.
+type interfaceDefault struct {
+	iface reflect.Type
+	value any
+}
+
+// WithInterfaceDefault registers a struct default for every struct implementing the interface I, merged by field name
+// after the defaults of the concrete type. Fields of the same name but a different type fail with ErrTypeMismatch.
+//
+//	konfetty.WithInterfaceDefault[Config, Animal](processor, AnimalDefaults{Legs: 4})
+func WithInterfaceDefault[T, I any](p *Processor[T], value any) *Processor[T] {
+	iface := reflect.TypeFor[I]()
+	if iface.Kind() != reflect.Interface {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("%w: interface default for %s, which is not an interface",
+			ErrInvalidDefault, iface))
+		return p
+	}
+
+	if t := reflect.TypeOf(value); t == nil || derefType(t).Kind() != reflect.Struct {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("%w: interface default for %s must be a struct or a pointer "+
+			"to a struct, but is of type %v", ErrInvalidDefault, iface, t))
+		return p
+	}
+
+	p.builder.interfaceDefaults = append(p.builder.interfaceDefaults, interfaceDefault{iface: iface, value: value})
+	return p
+}
+
+// applyInterfaceDefaults merges the interface defaults whose interface is implemented by the struct v into it.
+func (d *defaulter) applyInterfaceDefaults(v reflect.Value, path string) error {
+	t := v.Type()
+	for i, id := range d.interfaceDefaults {
+		if !t.Implements(id.iface) && !reflect.PointerTo(t).Implements(id.iface) {
+			continue
+		}
+
+		src := reflect.ValueOf(id.value)
+		if src = dereference(src); !src.IsValid() {
+			continue
+		}
+
+		d.origin = registeredDefault{value: id.value, index: i}
+		if err := d.mergeByName(v, src, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeByName merges the fields of the struct src into the fields of the same name of the struct dst, which may be of
+// a different type.
+func (d *defaulter) mergeByName(dst, src reflect.Value, path string) error {
+	dstFields := d.tags.fields(dst.Type())
+	for i, srcField := range d.tags.fields(src.Type()) {
+		if !srcField.IsExported() {
+			continue
+		}
+
+		j := fieldIndex(dstFields, srcField.Name)
+		if j < 0 {
+			continue
+		}
+
+		fieldPath := joinPath(path, d.tags.fieldName(dstFields[j].StructField))
+		if dstFields[j].Type != srcField.Type {
+			return fmt.Errorf("%s: %w: interface default of type %s has a field of type %s, but the field is of type %s",
+				fieldPath, ErrTypeMismatch, src.Type(), srcField.Type, dstFields[j].Type)
+		}
+
+		if err := d.mergeField(dst.Field(j), src.Field(i), dstFields[j], fieldPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldIndex returns the index of the field with the given name, or -1.
+func fieldIndex(fields []fieldInfo, name string) int {
+	for i, field := range fields {
+		if field.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,92 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type Animal interface {
+	Sound() string
+}
+
+type Dog struct {
+	Name string
+	Legs int
+	Bark string
+}
+
+func (d *Dog) Sound() string { return d.Bark }
+
+type Cat struct {
+	Name string
+	Legs int
+}
+
+func (Cat) Sound() string { return "meow" }
+
+type Zoo struct {
+	Animals []Animal
+}
+
+func TestWithInterfaceDefault(t *testing.T) {
+	t.Parallel()
+
+	type AnimalDefaults struct {
+		Name  string
+		Legs  int
+		Wings int
+	}
+
+	t.Run("SharedFields", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(&Zoo{Animals: []Animal{&Dog{Name: "Buddy"}, Cat{}, &Cat{Legs: 3}}}).
+			WithDefaults(Dog{Bark: "woof", Legs: 5})
+		konfetty.WithInterfaceDefault[Zoo, Animal](processor, AnimalDefaults{Name: "unnamed", Legs: 4, Wings: 2})
+		konfetty.WithInterfaceDefault[Zoo, Animal](processor, &AnimalDefaults{Name: "ignored"})
+
+		cfg, report, err := processor.BuildWithReport()
+		must.NoError(t, err)
+
+		// The defaults of the concrete type take precedence over the interface default.
+		must.Eq(t, []Animal{
+			&Dog{Name: "Buddy", Legs: 5, Bark: "woof"},
+			Cat{Name: "unnamed", Legs: 4},
+			&Cat{Name: "unnamed", Legs: 3},
+		}, cfg.Animals)
+		must.Eq(t, "Animals[1].Name:  -> unnamed (default #0 of type konfetty_test.AnimalDefaults)",
+			report.Changes[2].String())
+	})
+
+	t.Run("TypeMismatch", func(t *testing.T) {
+		t.Parallel()
+
+		type Mismatch struct {
+			Legs string
+		}
+
+		processor := konfetty.FromStruct(&Zoo{Animals: []Animal{Cat{}}})
+		konfetty.WithInterfaceDefault[Zoo, Animal](processor, Mismatch{Legs: "four"})
+
+		_, err := processor.Build()
+		must.ErrorIs(t, err, konfetty.ErrTypeMismatch)
+		must.ErrorContains(t, err, "Animals[0].Legs:")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(&Zoo{})
+		konfetty.WithInterfaceDefault[Zoo, Cat](processor, AnimalDefaults{})
+		_, err := processor.Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidDefault)
+
+		processor = konfetty.FromStruct(&Zoo{})
+		konfetty.WithInterfaceDefault[Zoo, Animal](processor, 4)
+		_, err = processor.Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidDefault)
+	})
+}
@@ -0,0 +1,119 @@
+// Package convert parses the textual form of scalar values, e.g. from command-line flags or environment variables, into
+// values of reflected types.
+package convert
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// durationType is the type of time.Duration, which is parsed from its textual form instead of as a plain integer.
+//
+//nolint:gochecknoglobals // Immutable type descriptor
+var durationType = reflect.TypeFor[time.Duration]()
+
+// urlType is the type of url.URL, which doesn't implement encoding.TextUnmarshaler and is parsed with url.Parse
+// instead.
+//
+//nolint:gochecknoglobals // Immutable type descriptor
+var urlType = reflect.TypeFor[url.URL]()
+
+//nolint:gochecknoglobals // Immutable type descriptor
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// IsTextUnmarshaler reports whether pointers to values of type t implement encoding.TextUnmarshaler.
+func IsTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// IsTextual reports whether values of type t are opaque values parsed from their textual form as a whole, i.e. types
+// implementing encoding.TextUnmarshaler, e.g. net.IP, and url.URL.
+func IsTextual(t reflect.Type) bool {
+	return t == urlType || IsTextUnmarshaler(t)
+}
+
+// CanSetString reports whether values of type t can be parsed by SetString.
+func CanSetString(t reflect.Type) bool {
+	if IsTextual(t) {
+		return true
+	}
+
+	//nolint:exhaustive // Only scalar kinds have a textual form
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetString parses s according to the type of v and stores the result in v. Types implementing
+// encoding.TextUnmarshaler, e.g. custom enums or net.IP, parse s themselves. URLs are parsed with url.Parse, durations
+// with time.ParseDuration and all other types with the strconv function matching their kind.
+func SetString(v reflect.Value, s string) error {
+	if v.Type() == urlType {
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*u))
+
+		return nil
+	}
+
+	if v.CanAddr() && IsTextUnmarshaler(v.Type()) {
+		//nolint:errcheck,forcetypeassert // The type implements encoding.TextUnmarshaler
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	//nolint:exhaustive // Only scalar kinds have a textual form
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+
+			return nil
+		}
+
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("can't parse a value of type %s from a string", v.Type())
+	}
+
+	return nil
+}
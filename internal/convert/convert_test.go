@@ -0,0 +1,89 @@
+package convert_test
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty/internal/convert"
+)
+
+type LogLevel int
+
+const (
+	LevelInfo LogLevel = iota
+	LevelDebug
+)
+
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "info":
+		*l = LevelInfo
+	case "debug":
+		*l = LevelDebug
+	default:
+		return fmt.Errorf("unknown log level %q", text)
+	}
+
+	return nil
+}
+
+func TestSetString(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name    string
+		Enabled bool
+		Port    int16
+		Mask    uint8
+		Ratio   float32
+		Timeout time.Duration
+		Level   LogLevel
+		IP      net.IP
+		URL     url.URL
+	}
+
+	var config Config
+	v := reflect.ValueOf(&config).Elem()
+
+	values := map[string]string{
+		"Name":    "app",
+		"Enabled": "true",
+		"Port":    "0x1F",
+		"Mask":    "255",
+		"Ratio":   "0.5",
+		"Timeout": "1m",
+		"Level":   "DEBUG",
+		"IP":      "10.0.0.1",
+		"URL":     "https://example.com/api",
+	}
+
+	for name, value := range values {
+		field := v.FieldByName(name)
+		must.True(t, convert.CanSetString(field.Type()), must.Sprintf("field %s", name))
+		must.NoError(t, convert.SetString(field, value), must.Sprintf("field %s", name))
+	}
+
+	must.Eq(t, Config{
+		Name:    "app",
+		Enabled: true,
+		Port:    31,
+		Mask:    255,
+		Ratio:   0.5,
+		Timeout: time.Minute,
+		Level:   LevelDebug,
+		IP:      net.ParseIP("10.0.0.1"),
+		URL:     url.URL{Scheme: "https", Host: "example.com", Path: "/api"},
+	}, config)
+
+	must.ErrorContains(t, convert.SetString(v.FieldByName("Level"), "trace"), `unknown log level "trace"`)
+	must.Error(t, convert.SetString(v.FieldByName("Mask"), "256"))
+	must.Error(t, convert.SetString(v.FieldByName("URL"), "http://[::1"))
+	must.False(t, convert.CanSetString(reflect.TypeFor[[]string]()))
+}
@@ -0,0 +1,116 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// maxInterpolationDepth limits how deep interpolated values may reference each other.
+const maxInterpolationDepth = 32
+
+// tokenPattern matches interpolation tokens referencing other fields, e.g. `{General.HomeName}` or `{Rooms[0].Name}`.
+var tokenPattern = regexp.MustCompile(`\{([A-Za-z_][\w.\[\]-]*)\}`)
+
+// interpolator replaces tokens in string fields with the values of the fields they reference.
+type interpolator struct {
+	root      reflect.Value
+	strict    bool
+	resolving []string
+
+	// report receives a warning for every token that is left unresolved, if set.
+	report *Report
+}
+
+// interpolate resolves all tokens in the string fields of the config. Referenced fields containing tokens themselves
+// are resolved first. Tokens referencing unknown fields are left as they are, unless strict is set, in which case
+// an error is returned. Tokens left as they are are reported as warnings, if report is set.
+func interpolate(config any, strict bool, tags tagResolver, report *Report) error {
+	in := &interpolator{
+		root:   reflect.ValueOf(config),
+		strict: strict,
+		report: report,
+	}
+
+	// Tokens reference fields by their Go names, so the paths used for detecting cycles have to use them, too.
+	tags.pathTag = ""
+
+	return traverse(in.root, tags, func(v reflect.Value, path string) error {
+		if v.Kind() != reflect.String || !v.CanSet() {
+			return nil
+		}
+
+		resolved, err := in.resolve(v.String(), path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetString(resolved)
+
+		return nil
+	})
+}
+
+func (in *interpolator) resolve(s, path string) (string, error) {
+	if !strings.Contains(s, "{") {
+		return s, nil
+	}
+
+	if len(in.resolving) >= maxInterpolationDepth {
+		return "", fmt.Errorf("%w: interpolation exceeds a depth of %d", ErrCircularReference, maxInterpolationDepth)
+	}
+
+	in.resolving = append(in.resolving, path)
+	defer func() { in.resolving = in.resolving[:len(in.resolving)-1] }()
+
+	var err error
+	resolved := tokenPattern.ReplaceAllStringFunc(s, func(token string) string {
+		if err != nil {
+			return token
+		}
+
+		var value string
+		value, err = in.resolveToken(token[1 : len(token)-1])
+		if err != nil {
+			if !in.strict && !errors.Is(err, ErrCircularReference) {
+				in.report.warn(WarningUnresolvedToken, path, fmt.Sprintf("token %s is left as is: %v", token, err))
+				err = nil
+			}
+
+			return token
+		}
+
+		return value
+	})
+
+	return resolved, err
+}
+
+func (in *interpolator) resolveToken(ref string) (string, error) {
+	for _, path := range in.resolving {
+		if path == ref {
+			return "", fmt.Errorf("%w: {%s} references itself", ErrCircularReference, ref)
+		}
+	}
+
+	v, err := lookupPath(in.root, ref)
+	if err != nil {
+		return "", err
+	}
+
+	v = indirect(v)
+	if !v.IsValid() {
+		return "", nil
+	}
+
+	if !v.CanInterface() {
+		return "", fmt.Errorf("%w: %s is unexported", ErrUnknownPath, ref)
+	}
+
+	if v.Kind() == reflect.String {
+		return in.resolve(v.String(), ref)
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
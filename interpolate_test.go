@@ -0,0 +1,91 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type InterpolationGeneral struct {
+	HomeName string
+	Floors   int
+}
+
+type InterpolationServer struct {
+	Name        string
+	Description string
+}
+
+type InterpolationConfig struct {
+	General InterpolationGeneral
+	Server  InterpolationServer
+	Rooms   []string
+	Labels  map[string]string
+}
+
+func TestWithInterpolation(t *testing.T) {
+	t.Parallel()
+
+	config := &InterpolationConfig{
+		General: InterpolationGeneral{HomeName: "My Home", Floors: 2},
+		Rooms:   []string{"{General.HomeName} Kitchen"},
+		Labels:  map[string]string{"home": "{General.HomeName}"},
+	}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(InterpolationServer{
+			Name:        "{General.HomeName} Controller",
+			Description: "{Server.Name} for {General.Floors} floors",
+		}).
+		WithInterpolation().
+		Build()
+
+	must.NoError(t, err)
+	must.Eq(t, "My Home Controller", result.Server.Name)
+	must.Eq(t, "My Home Controller for 2 floors", result.Server.Description)
+	must.Eq(t, []string{"My Home Kitchen"}, result.Rooms)
+	must.Eq(t, map[string]string{"home": "My Home"}, result.Labels)
+}
+
+func TestWithInterpolationDisabled(t *testing.T) {
+	t.Parallel()
+
+	config := &InterpolationConfig{General: InterpolationGeneral{HomeName: "My Home"}}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(InterpolationServer{Name: "{General.HomeName} Controller"}).
+		Build()
+
+	must.NoError(t, err)
+	must.Eq(t, "{General.HomeName} Controller", result.Server.Name)
+}
+
+func TestWithInterpolationUnknownPath(t *testing.T) {
+	t.Parallel()
+
+	config := &InterpolationConfig{Server: InterpolationServer{Name: "{General.Unknown} Controller"}}
+
+	result, err := konfetty.FromStruct(config).WithInterpolation().Build()
+	must.NoError(t, err)
+	must.Eq(t, "{General.Unknown} Controller", result.Server.Name)
+
+	_, err = konfetty.FromStruct(config).WithInterpolation().WithStrict().Build()
+	must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+	must.ErrorContains(t, err, "Server.Name")
+}
+
+func TestWithInterpolationCycle(t *testing.T) {
+	t.Parallel()
+
+	config := &InterpolationConfig{
+		Server: InterpolationServer{
+			Name:        "{Server.Description}",
+			Description: "{Server.Name}",
+		},
+	}
+
+	_, err := konfetty.FromStruct(config).WithInterpolation().Build()
+	must.ErrorIs(t, err, konfetty.ErrCircularReference)
+}
@@ -0,0 +1,17 @@
+module github.com/nikoksr/konfetty/koanfx
+
+go 1.22.5
+
+require (
+	github.com/go-viper/mapstructure/v2 v2.0.0-alpha.1
+	github.com/knadh/koanf/v2 v2.1.1
+	github.com/nikoksr/konfetty v0.2.0
+	github.com/shoenig/test v1.11.0
+)
+
+require (
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/knadh/koanf/maps v0.1.1 // indirect
+	github.com/mitchellh/copystructure v1.2.0 // indirect
+	github.com/mitchellh/reflectwalk v1.0.2 // indirect
+)
@@ -0,0 +1,61 @@
+// Package koanfx provides a konfetty provider that loads the data-structure from a koanf instance. It lives in its own
+// module, so that the core konfetty module stays free of dependencies.
+package koanfx
+
+import (
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/nikoksr/konfetty"
+)
+
+// Provider loads a T by unmarshalling a koanf instance. It implements konfetty.Provider.
+type Provider[T any] struct {
+	k      *koanf.Koanf
+	path   string
+	strict bool
+}
+
+var _ konfetty.Provider[struct{}] = (*Provider[struct{}])(nil)
+
+// New returns a Provider that unmarshals the config at path from k, or all of k if path is empty. The unmarshalling
+// happens on every build, so changes loaded into k in the meantime are picked up.
+//
+//	k := koanf.New(".")
+//	if err := k.Load(file.Provider("config.yaml"), yaml.Parser()); err != nil { ... }
+//	processor := konfetty.FromProvider(koanfx.New[AppConfig](k, ""))
+func New[T any](k *koanf.Koanf, path string) *Provider[T] {
+	return &Provider[T]{k: k, path: path}
+}
+
+// WithStrict makes Load fail if the koanf instance holds keys that don't map to any field of T, which usually hints
+// at a typo in the config file. The error lists the unknown keys.
+func (p *Provider[T]) WithStrict() *Provider[T] {
+	p.strict = true
+	return p
+}
+
+// Load unmarshals the config from the koanf instance.
+func (p *Provider[T]) Load() (T, error) {
+	var cfg T
+
+	var conf koanf.UnmarshalConf
+	if p.strict {
+		// Mirrors the decoder koanf uses by default, but rejects unused keys.
+		conf.DecoderConfig = &mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.TextUnmarshallerHookFunc(),
+			),
+			Result:           &cfg,
+			WeaklyTypedInput: true,
+			ErrorUnused:      true,
+		}
+	}
+
+	if err := p.k.UnmarshalWithConf(p.path, &cfg, conf); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,65 @@
+package koanfx_test
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+	"github.com/nikoksr/konfetty/koanfx"
+)
+
+type DatabaseConfig struct {
+	Host string `koanf:"host"`
+	Port int    `koanf:"port"`
+}
+
+type AppConfig struct {
+	Database DatabaseConfig `koanf:"database"`
+	LogLevel string         `koanf:"log_level"`
+}
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+
+	k := koanf.New(".")
+	must.NoError(t, k.Set("database.port", 6543))
+	must.NoError(t, k.Set("log_level", "debug"))
+
+	result, err := konfetty.FromProvider(koanfx.New[AppConfig](k, "")).
+		WithDefaults(DatabaseConfig{Host: "localhost", Port: 5432}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &AppConfig{Database: DatabaseConfig{Host: "localhost", Port: 6543}, LogLevel: "debug"}, result)
+
+	database, err := koanfx.New[DatabaseConfig](k, "database").Load()
+	must.NoError(t, err)
+	must.Eq(t, DatabaseConfig{Port: 6543}, database)
+}
+
+func TestProviderError(t *testing.T) {
+	t.Parallel()
+
+	k := koanf.New(".")
+	must.NoError(t, k.Set("database.port", "not a number"))
+
+	_, err := konfetty.FromProvider(koanfx.New[AppConfig](k, "")).Build()
+	must.ErrorContains(t, err, "load: from provider")
+	must.ErrorContains(t, err, "database.port")
+}
+
+func TestProviderStrict(t *testing.T) {
+	t.Parallel()
+
+	k := koanf.New(".")
+	must.NoError(t, k.Set("database.port", 6543))
+	must.NoError(t, k.Set("database.hots", "db.internal"))
+
+	result, err := koanfx.New[AppConfig](k, "").Load()
+	must.NoError(t, err)
+	must.Eq(t, 6543, result.Database.Port)
+
+	_, err = konfetty.FromProvider(koanfx.New[AppConfig](k, "").WithStrict()).Build()
+	must.ErrorContains(t, err, "hots")
+}
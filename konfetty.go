@@ -3,33 +3,115 @@
 package konfetty
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
-// Provider defines an interface for loading structured data.
-type Provider[T any] interface {
-	Load() (T, error)
-}
-
 // dataSource is an internal type to represent the source of data.
 type dataSource[T any] struct {
-	data       *T
+	data *T
+	// mu serializes builds working on data, which they mutate in place unless they work on a deep copy.
+	mu *sync.Mutex
+
 	loaderFunc func() (T, error)
 	provider   Provider[T]
+	providers  []Provider[T]
 }
 
 // Builder orchestrates the building process. It manages the data source, defaults, transformations, and validations.
 type Builder[T any] struct {
-	source    dataSource[T]
-	defaults  map[reflect.Type][]any
-	transform func(*T)
-	validate  func(*T) error
+	source       dataSource[T]
+	defaults     map[reflect.Type][]any
+	computed     map[reflect.Type][]computedDefault
+	catchAll     func(reflect.Type) (any, bool)
+	transformers []func(*T) error
+	validators   []validator[T]
+	hooks        []func(*T)
+	onLoaded     []func(*T)
+	retry        retryPolicy
+	timeout      time.Duration
+	stages       []Stage
+	profiles     profiles
+	scoped       []scopedDefaults
+	zeroFuncs    map[reflect.Type]func(reflect.Value) bool
+	base         *T
+	concretes    []concreteType
+	normalizers  []pathNormalizer
+	mapDefaults  []pathMapDefaults
+	fallback     *T
+	maxDepth     int
+
+	// validationWorkers is the number of values validated concurrently by WithValidatorForEach validators, see
+	// WithParallelValidation. Values are validated one after another if it's zero or one.
+	validationWorkers int
+
+	// interfaceDefaults apply to every struct implementing their interface, see WithInterfaceDefault.
+	interfaceDefaults []interfaceDefault
+
+	// overridden holds the types of the defaults replaced by OverrideDefaults, which global defaults don't apply to.
+	overridden map[reflect.Type]bool
+
+	// errs collects configuration errors, e.g. invalid paths, which are returned by Build.
+	errs []error
+
+	tagKeys []string
+
+	// typeCache caches the field metadata of the processed types across builds, if enabled. It depends on the tag keys
+	// and is replaced whenever they change.
+	typeCache *typeCache
+
+	// pathTag is the struct tag key the field names in reported paths are read from, see WithPathTag.
+	pathTag string
+
+	deepCopy         bool
+	interpolate      bool
+	strict           bool
+	resolveLazies    bool
+	verifyIdempotent bool
+	checkConflicts   bool
+	tagDefaults      bool
+	recoverPanics    bool
+	redefault        bool
+	noGlobalDefaults bool
+	emptyAsUnset     bool
+	foldMapKeys      bool
+}
+
+// validator is a validation function that only runs if its condition holds. A nil condition always holds. Validators
+// comparing the processed data-structure to the loaded one set diffFn instead of fn, validators walking the
+// data-structure set eachFn, which receives the processor's tag resolver and the number of workers validating values
+// concurrently. The validator set with WithValidator is marked as primary, so that it can be replaced.
+type validator[T any] struct {
+	primary bool
+	cond    func(*T) bool
+	fn      func(*T) error
+	diffFn  func(original, final *T) error
+	eachFn  func(*T, tagResolver, int) error
 }
 
 // Processor exposes methods for further data-structure processing. It wraps a Builder and provides a fluent interface
 // for configuration setup.
+//
+// Configuring a processor isn't safe for concurrent use, but once configured, a processor can be built from multiple
+// goroutines at the same time, as long as its loader, providers, transformers and validators are safe for concurrent
+// use. Registered defaults are copied into the data-structure and never modified by a build. Since builds of FromStruct
+// processors mutate the struct in place, they are serialized and their results share data with each other, unless
+// WithDeepCopy is used.
+//
+// Nil functions passed to its options, e.g. a nil transformer or validator, are ignored, which makes assembling
+// pipelines from optional parts safe. The only exceptions are the unmarshal functions of FromBytes, FromFile and
+// WithDefaultsFromBytes, which are required to decode anything; Build fails without them instead.
 type Processor[T any] struct {
 	builder *Builder[T]
 }
@@ -41,7 +123,7 @@ type Processor[T any] struct {
 func FromStruct[T any](config *T) *Processor[T] {
 	return &Processor[T]{
 		builder: &Builder[T]{
-			source: dataSource[T]{data: config},
+			source: dataSource[T]{data: config, mu: &sync.Mutex{}},
 		},
 	}
 }
@@ -58,6 +140,53 @@ func FromLoaderFunc[T any](loader func() (T, error)) *Processor[T] {
 	}
 }
 
+// FromBytes initializes a Processor that loads the data-structure by decoding data with the given unmarshal function,
+// which allows plugging in any format, e.g. JSON, YAML or TOML. The data is decoded anew on every build; unmarshal
+// errors are returned by Build. Without an unmarshal function, there's nothing to load the data-structure with, so
+// Build returns ErrNoDataSource.
+//
+//	processor := konfetty.FromBytes[MyConfig](data, json.Unmarshal)
+func FromBytes[T any](data []byte, unmarshal func([]byte, any) error) *Processor[T] {
+	if unmarshal == nil {
+		return FromLoaderFunc[T](nil)
+	}
+
+	return FromLoaderFunc(func() (T, error) {
+		var cfg T
+		if err := unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("unmarshal: %w", err)
+		}
+
+		return cfg, nil
+	})
+}
+
+// FromFile initializes a Processor that loads the data-structure by reading the file at path and decoding it with the
+// given unmarshal function, like FromBytes. The file is read anew on every build; read and unmarshal errors are
+// returned by Build. Without an unmarshal function, Build returns ErrNoDataSource.
+//
+//	processor := konfetty.FromFile[MyConfig]("config.json", json.Unmarshal)
+func FromFile[T any](path string, unmarshal func([]byte, any) error) *Processor[T] {
+	if unmarshal == nil {
+		return FromLoaderFunc[T](nil)
+	}
+
+	return FromLoaderFunc(func() (T, error) {
+		var cfg T
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read file: %w", err)
+		}
+
+		if err = unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("unmarshal %s: %w", path, err)
+		}
+
+		return cfg, nil
+	})
+}
+
 // FromProvider initializes a Processor with a Provider.
 //
 //	provider := MyConfigProvider{}
@@ -70,8 +199,45 @@ func FromProvider[T any](provider Provider[T]) *Processor[T] {
 	}
 }
 
+// FromProviders initializes a Processor with multiple Providers whose results are deep-merged in order. Later
+// providers take precedence: every non-zero value loaded by a later provider overrides the value loaded by earlier
+// ones, while zero values never clobber earlier values. Nested structs, maps and pointers to structs are merged
+// recursively, slices and all other values are replaced as a whole. Nil providers are skipped; without any other
+// provider, Build fails with ErrNoDataSource.
+//
+//	processor := konfetty.FromProviders(fileProvider, envProvider)
+func FromProviders[T any](providers ...Provider[T]) *Processor[T] {
+	nonNil := make([]Provider[T], 0, len(providers))
+	for _, provider := range providers {
+		if provider != nil {
+			nonNil = append(nonNil, provider)
+		}
+	}
+
+	return &Processor[T]{
+		builder: &Builder[T]{
+			source: dataSource[T]{providers: nonNil},
+		},
+	}
+}
+
+// WithProviderRetry makes the processor retry failed provider loads up to the given number of attempts in total. The
+// wait between attempts starts at backoff and doubles after every failed attempt, up to a minute. Retries stop early
+// if the build's context is done.
+func (p *Processor[T]) WithProviderRetry(attempts int, backoff time.Duration) *Processor[T] {
+	p.builder.retry = retryPolicy{attempts: attempts, backoff: backoff}
+	return p
+}
+
 // WithDefaults adds default values to the processing pipeline. Multiple defaults can be provided and will be applied
-// in order.
+// in order. Only structs, pointers to structs, maps and values of named scalar types can act as defaults; other values
+// make Build fail with ErrInvalidDefault. Structurally equal defaults of the same type, e.g. from composed processors,
+// are applied only once, unless the type has fields tagged with `konfetty:"merge=add"`.
+//
+// A value of a named scalar type, e.g. `Port(8080)` for `type Port int`, is a default for every unset value of that
+// type. Struct defaults providing a value for a field of such a type take precedence, as defaults of outer types win
+// over defaults of inner types. Common named scalar types of the standard library, like time.Duration, are shared by
+// unrelated values, so they can't act as defaults.
 func (p *Processor[T]) WithDefaults(defaultValues ...any) *Processor[T] {
 	if p.builder.defaults == nil {
 		p.builder.defaults = make(map[reflect.Type][]any)
@@ -79,58 +245,917 @@ func (p *Processor[T]) WithDefaults(defaultValues ...any) *Processor[T] {
 
 	for _, dv := range defaultValues {
 		t := reflect.TypeOf(dv)
-		p.builder.defaults[t] = append(p.builder.defaults[t], dv)
+		if err := checkDefaultType(t); err != nil {
+			p.builder.errs = append(p.builder.errs, err)
+			continue
+		}
+
+		p.builder.defaults[t] = appendDefault(p.builder.defaults[t], dv)
 	}
 
 	return p
 }
 
-// WithTransformer sets a custom transformation function to be applied to the data-structure.
+// OverrideDefaults is like WithDefaults, but replaces the defaults registered earlier for the same types, including
+// global defaults, instead of adding to them. Defaults registered for a struct type and a pointer to it are replaced
+// together, as they apply to the same values. Multiple defaults of the same type passed to a single call are all kept.
+// This gives deterministic control when composing a processor from multiple layers.
+//
+//	processor.WithDefaults(baseDefaults).OverrideDefaults(DatabaseConfig{Host: "db.internal"})
+func (p *Processor[T]) OverrideDefaults(defaultValues ...any) *Processor[T] {
+	for _, dv := range defaultValues {
+		t := reflect.TypeOf(dv)
+		if t == nil {
+			continue
+		}
+
+		counterpart := reflect.PointerTo(t)
+		if t.Kind() == reflect.Ptr {
+			counterpart = t.Elem()
+		}
+
+		delete(p.builder.defaults, t)
+		delete(p.builder.defaults, counterpart)
+
+		if p.builder.overridden == nil {
+			p.builder.overridden = make(map[reflect.Type]bool)
+		}
+		p.builder.overridden[t] = true
+		p.builder.overridden[counterpart] = true
+	}
+
+	return p.WithDefaults(defaultValues...)
+}
+
+// checkDefaultType reports whether values of type t can act as defaults.
+func checkDefaultType(t reflect.Type) error {
+	if t == nil {
+		return fmt.Errorf("%w: nil", ErrInvalidDefault)
+	}
+
+	//nolint:exhaustive // Only structs, pointers to structs, maps and named scalars can be merged into a config
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return nil
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			return nil
+		}
+	default:
+		if isNamedScalar(t) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: values of type %s can't act as defaults, use a struct, pointer to a struct, map or named "+
+		"scalar type instead", ErrInvalidDefault, t)
+}
+
+// appendDefault appends the default dv to the defaults of its type, dropping an earlier one that is structurally equal
+// to it, which commonly happens when composing processors. Later defaults take precedence, so dropping the earlier
+// copy doesn't change the result, but every default is merged once and reported once. Types with fields merged with
+// `merge=add` are exempt, as every copy adds to those fields.
+func appendDefault(values []any, dv any) []any {
+	if addsValues(reflect.TypeOf(dv), make(map[reflect.Type]bool)) {
+		return append(values, dv)
+	}
+
+	values = slices.DeleteFunc(values, func(value any) bool { return reflect.DeepEqual(value, dv) })
+
+	return append(values, dv)
+}
+
+// addsValues reports whether a field of the type t or of a struct nested in it is merged with `merge=add`. The raw
+// tags are checked, so that the result doesn't depend on the tag keys configured later on.
+func addsValues(t reflect.Type, visited map[reflect.Type]bool) bool {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if strings.Contains(string(field.Tag), "merge="+mergeAdd) || addsValues(field.Type, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNamedScalar reports whether t is a defined type with a scalar underlying type, e.g. `type Port int`. Predeclared
+// types like int are unnamed in this sense, as a default for every int of a data-structure makes little sense. The
+// same goes for the common scalar types of the standard library, see sharedScalarTypes.
+func isNamedScalar(t reflect.Type) bool {
+	return t.PkgPath() != "" && !sharedScalarTypes[t] && isScalar(t.Kind())
+}
+
+// sharedScalarTypes are the named scalar types of the standard library that are used for unrelated values alike, e.g.
+// time.Duration for every kind of timeout, so they can't act as defaults.
+//
+//nolint:gochecknoglobals // Immutable set of type descriptors
+var sharedScalarTypes = map[reflect.Type]bool{
+	reflect.TypeFor[time.Duration](): true,
+	reflect.TypeFor[time.Month]():    true,
+	reflect.TypeFor[time.Weekday]():  true,
+	reflect.TypeFor[fs.FileMode]():   true,
+	reflect.TypeFor[slog.Level]():    true,
+}
+
+// isScalar reports whether values of kind k are scalars, i.e. booleans, numbers and strings.
+func isScalar(k reflect.Kind) bool {
+	//nolint:exhaustive // Only scalar kinds
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithTypedDefault is the type-safe counterpart of WithDefaults for registering a single default. Both forms can be
+// mixed freely.
+//
+//	processor := konfetty.FromStruct(cfg)
+//	konfetty.WithTypedDefault(processor, DatabaseConfig{Port: 5432})
+func WithTypedDefault[T, U any](p *Processor[T], value U) *Processor[T] {
+	return p.WithDefaults(value)
+}
+
+// WithDefaultsFromJSON unmarshals the JSON document into a T and registers it as a default for the whole
+// data-structure, just like passing it to WithDefaults. Fields missing from the document are zero and don't clobber
+// loaded values. Unmarshal errors are returned by Build.
+func (p *Processor[T]) WithDefaultsFromJSON(data []byte) *Processor[T] {
+	return p.WithDefaultsFromBytes(data, json.Unmarshal)
+}
+
+// WithDefaultsFromBytes is like WithDefaultsFromJSON, but decodes the document using the given unmarshal function. This
+// allows keeping defaults in any format without konfetty depending on a decoder, e.g. YAML:
+//
+//	processor.WithDefaultsFromBytes(defaultsYAML, yaml.Unmarshal)
+func (p *Processor[T]) WithDefaultsFromBytes(data []byte, unmarshal func([]byte, any) error) *Processor[T] {
+	if unmarshal == nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("decode defaults: %w: nil unmarshal function", ErrInvalidDefault))
+		return p
+	}
+
+	var defaults T
+	if err := unmarshal(data, &defaults); err != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("decode defaults: %w", err))
+		return p
+	}
+
+	return p.WithDefaults(defaults)
+}
+
+// WithCatchAllDefault sets a function that supplies defaults for struct types without registered defaults, which
+// enables rule-based defaulting. Whenever the defaulting pass reaches a struct of a type no default was registered
+// for, fn is called with the type. It returns the default for the type, either as a value or pointer, or false if
+// there is none.
+//
+//	processor.WithCatchAllDefault(func(t reflect.Type) (any, bool) {
+//		if strings.HasSuffix(t.Name(), "Device") {
+//			return defaultDevices[t], true
+//		}
+//		return nil, false
+//	})
+func (p *Processor[T]) WithCatchAllDefault(fn func(reflect.Type) (any, bool)) *Processor[T] {
+	p.builder.catchAll = fn
+	return p
+}
+
+// WithTransformer adds a custom transformation function to be applied to the data-structure. Multiple transformers
+// can be added and will be run in order.
 func (p *Processor[T]) WithTransformer(fn func(*T)) *Processor[T] {
-	p.builder.transform = fn
+	if fn == nil {
+		return p
+	}
+
+	return p.WithTransformerE(func(cfg *T) error {
+		fn(cfg)
+		return nil
+	})
+}
+
+// WithTransformerE is like WithTransformer, but the transformation function may fail, which aborts the build with the
+// returned error. Both kinds of transformers can be mixed and are run in the order they were added.
+func (p *Processor[T]) WithTransformerE(fn func(*T) error) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	p.builder.transformers = append(p.builder.transformers, fn)
 	return p
 }
 
-// WithValidator sets a custom validation function to be applied to the data-structure.
+// WithValidator sets a custom validation function to be applied to the data-structure, replacing the one set by an
+// earlier call. It runs after the built-in validations: the options declared in struct tags, e.g.
+// `konfetty:"pattern=^[a-z]+$"`, and the Validate methods of values implementing Validatable. Validators added with
+// WithValidators, WithValidatorWhen and the like are kept, and all of them run in the order they were first added.
 func (p *Processor[T]) WithValidator(fn func(*T) error) *Processor[T] {
-	p.builder.validate = fn
+	if fn == nil {
+		return p
+	}
+
+	for i, v := range p.builder.validators {
+		if v.primary {
+			p.builder.validators[i].fn = fn
+			return p
+		}
+	}
+
+	p.builder.validators = append(p.builder.validators, validator[T]{primary: true, fn: fn})
+	return p
+}
+
+// WithOnLoaded adds a function that is called with the data-structure right after it was loaded from its source and
+// the base was applied, before any defaults, transformers or validators ran. It's meant for observing exactly what was
+// loaded, e.g. for audit logging; clone the data-structure if it's kept beyond the call, as the build processes it in
+// place afterwards. Multiple functions can be added and will be run in order.
+//
+//	processor.WithOnLoaded(func(cfg *MyConfig) {
+//		audit.Record(cfg.Clone())
+//	})
+func (p *Processor[T]) WithOnLoaded(fn func(*T)) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	p.builder.onLoaded = append(p.builder.onLoaded, fn)
 	return p
 }
 
+// WithPostValidateHook adds a function that is called with the final data-structure after the build succeeded, i.e.
+// after all validators passed. It's meant for side effects, e.g. logging a summary or warming a cache, and isn't called
+// if any stage fails. Multiple hooks can be added and will be run in order.
+//
+//	processor.WithPostValidateHook(func(cfg *MyConfig) {
+//		slog.Info("config loaded", "env", cfg.Env)
+//	})
+func (p *Processor[T]) WithPostValidateHook(fn func(*T)) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	p.builder.hooks = append(p.builder.hooks, fn)
+	return p
+}
+
+// WithValidators adds validation functions to be applied to the data-structure, e.g. the ones returned by InRange and
+// OneOf. Unlike WithValidator, it never replaces validators added earlier. Validators run in the order they were added.
+//
+//	processor.WithValidators(
+//		konfetty.InRange[Config]("Server.Port", 1, 65535),
+//		konfetty.OneOf[Config]("Mode", "auto", "manual"),
+//	)
+func (p *Processor[T]) WithValidators(fns ...func(*T) error) *Processor[T] {
+	for _, fn := range fns {
+		if fn != nil {
+			p.builder.validators = append(p.builder.validators, validator[T]{fn: fn})
+		}
+	}
+
+	return p
+}
+
+// WithValidatorWhen adds a validation function that only runs if cond returns true for the processed data-structure.
+// This is useful for validating optional features only when they are enabled.
+//
+//	processor.WithValidatorWhen(
+//		func(cfg *MyConfig) bool { return cfg.TLS.Enabled },
+//		validateTLS,
+//	)
+func (p *Processor[T]) WithValidatorWhen(cond func(*T) bool, fn func(*T) error) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	p.builder.validators = append(p.builder.validators, validator[T]{cond: cond, fn: fn})
+	return p
+}
+
+// WithValidatorDiff adds a validation function that receives the data-structure as it was loaded, before any
+// processing happened, along with the processed one. This allows telling apart values supplied by the user from
+// values set by defaults or transformers, e.g. to reject a secret that was only satisfied by a default. Registering
+// such a validator makes every build keep a deep copy of the loaded data-structure, which doubles its memory footprint
+// for the duration of the build.
+//
+//	processor.WithValidatorDiff(func(original, final *MyConfig) error {
+//		if original.Secret == "" {
+//			return errors.New("secret must be set explicitly")
+//		}
+//		return nil
+//	})
+func (p *Processor[T]) WithValidatorDiff(fn func(original, final *T) error) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	p.builder.validators = append(p.builder.validators, validator[T]{diffFn: fn})
+	return p
+}
+
+// WithZeroFunc registers a function deciding whether values of type t are unset and get filled by defaults. By
+// default, a value is unset if it's the zero value of its type, see reflect.Value.IsZero. Custom zero funcs allow other
+// semantics, e.g. treating -1 as unset or an explicitly set zero as set. A value a zero func considers unset is only
+// replaced by a non-zero default.
+//
+//	processor.WithZeroFunc(reflect.TypeFor[time.Duration](), func(v reflect.Value) bool {
+//		return v.Int() < 0
+//	})
+func (p *Processor[T]) WithZeroFunc(t reflect.Type, fn func(reflect.Value) bool) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	if p.builder.zeroFuncs == nil {
+		p.builder.zeroFuncs = make(map[reflect.Type]func(reflect.Value) bool)
+	}
+
+	p.builder.zeroFuncs[t] = fn
+
+	return p
+}
+
+// WithEmptyAsUnset makes empty slices and maps count as unset, so that they are filled by defaults just like nil ones.
+// By default, only nil slices and maps are unset, so an explicitly empty list in a config file is kept. Zero funcs
+// registered with WithZeroFunc take precedence.
+func (p *Processor[T]) WithEmptyAsUnset() *Processor[T] {
+	p.builder.emptyAsUnset = true
+	return p
+}
+
+// WithKeyCaseInsensitiveDefaults makes map defaults compare string keys case-insensitively, so that a default entry
+// for "Timeout" isn't added to a map that already contains "timeout". This suits maps of case-insensitive keys, e.g.
+// header names. Maps with other key types are unaffected.
+func (p *Processor[T]) WithKeyCaseInsensitiveDefaults() *Processor[T] {
+	p.builder.foldMapKeys = true
+	return p
+}
+
+// WithMaxDepth limits how deep the defaults are applied into the data-structure, so that deeply nested recursive
+// values, e.g. a generated tree thousands of levels deep, fail the build with ErrMaxDepthExceeded instead of
+// overflowing the stack. Every struct, pointer, slice, map and interface on the way to a value counts as one level,
+// scalars don't. A limit of zero, the default, disables the check.
+func (p *Processor[T]) WithMaxDepth(n int) *Processor[T] {
+	if n < 0 {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("max depth: %d is negative", n))
+		return p
+	}
+
+	p.builder.maxDepth = n
+
+	return p
+}
+
+// WithValidatorForEach adds a validation function that is called for every value of type Elem in the processed
+// data-structure, including the ones stored in slices, maps and interfaces. Errors are prefixed with the values' paths.
+//
+//	konfetty.WithValidatorForEach(processor, func(light *LightDevice) error {
+//		if light.Brightness > 100 {
+//			return errors.New("brightness must be between 0 and 100")
+//		}
+//		return nil
+//	})
+func WithValidatorForEach[T, Elem any](p *Processor[T], fn func(*Elem) error) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	elemType := reflect.TypeFor[Elem]()
+
+	p.builder.validators = append(p.builder.validators, validator[T]{
+		eachFn: func(cfg *T, tags tagResolver, workers int) error {
+			var errs []pathError
+			var values []pathValue[Elem]
+			err := traverse(reflect.ValueOf(cfg), tags, func(v reflect.Value, path string) error {
+				if v.Type() != elemType || !v.CanAddr() {
+					return nil
+				}
+
+				//nolint:errcheck,forcetypeassert // The value is of type Elem
+				value := v.Addr().Interface().(*Elem)
+				if workers > 1 {
+					values = append(values, pathValue[Elem]{path: path, value: value})
+				} else if err := fn(value); err != nil {
+					errs = append(errs, pathError{path: path, err: err})
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if workers > 1 {
+				errs = validateConcurrently(values, fn, workers)
+			}
+
+			return joinPathErrors(errs)
+		},
+	})
+
+	return p
+}
+
+// WithTagPriority sets the struct tag keys konfetty reads its field options from, in order of priority. For every
+// field, the first key present is used. By default, only the `konfetty` key is checked.
+//
+//	processor.WithTagPriority("konfetty", "cfg", "default")
+func (p *Processor[T]) WithTagPriority(keys ...string) *Processor[T] {
+	p.builder.tagKeys = append([]string(nil), keys...)
+	if p.builder.typeCache != nil {
+		p.builder.typeCache = &typeCache{}
+	}
+
+	return p
+}
+
+// WithPathTag makes the processor render the paths in errors and reports using the field names of the given struct
+// tag, e.g. `json`, `mapstructure` or `koanf`, so that they match the keys of the config file. With the `json` tag,
+// `Database.Port` becomes `database.port`. Fields without the tag fall back to their Go name. Paths given to konfetty,
+// e.g. of computed defaults and interpolation tokens, always use the Go names.
+//
+//	processor.WithPathTag("koanf")
+func (p *Processor[T]) WithPathTag(tag string) *Processor[T] {
+	p.builder.pathTag = tag
+	return p
+}
+
+// WithTypeCache enables caching of the reflection metadata of the processed types, i.e. the fields of every struct
+// type and their parsed tags. The metadata is resolved on the first build and reused by later ones, which speeds up
+// processors that are built repeatedly, e.g. to reload the configuration. The cache is safe for concurrent builds.
+func (p *Processor[T]) WithTypeCache() *Processor[T] {
+	if p.builder.typeCache == nil {
+		p.builder.typeCache = &typeCache{}
+	}
+
+	return p
+}
+
+// WithDeepCopy makes the processor work on a deep copy of the loaded data-structure. Without it, slices, maps and
+// pointers of the input are shared with the result and get mutated in place, e.g. the struct passed to FromStruct.
+// In deep-copy mode, pointer values stored in maps are additionally copied per entry, so that entries sharing a
+// pointer are defaulted independently.
+func (p *Processor[T]) WithDeepCopy() *Processor[T] {
+	p.builder.deepCopy = true
+	return p
+}
+
+// WithInterpolation enables interpolation of string fields. After the defaults are applied, tokens like
+// `{General.HomeName}` in string fields are replaced by the value of the referenced field, e.g. the default
+// `"{General.HomeName} Controller"` becomes `"My Home Controller"`. Tokens referencing unknown fields are left
+// untouched, unless strict mode is enabled.
+func (p *Processor[T]) WithInterpolation() *Processor[T] {
+	p.builder.interpolate = true
+	return p
+}
+
+// WithStrict enables strict mode, which turns issues that are ignored by default into errors, e.g. interpolation
+// tokens referencing unknown fields. It also makes Build fail with ErrUnexportedDefault if defaults are registered for
+// the type of an unexported field, and with ErrUnreachableDefault if defaults are registered for a type that doesn't
+// occur in the data-structure, as such defaults can never be applied.
+func (p *Processor[T]) WithStrict() *Processor[T] {
+	p.builder.strict = true
+	return p
+}
+
+// WithResolveLazies enables an eager resolution pass at the end of the build. Every non-nil lazy field, i.e. a field
+// typed as `func() X`, is evaluated once and replaced by a func returning the computed value.
+func (p *Processor[T]) WithResolveLazies() *Processor[T] {
+	p.builder.resolveLazies = true
+	return p
+}
+
+// WithVerifyIdempotent enables a debug check that applies the defaults a second time to a copy of the defaulted
+// data-structure. If the second pass changes anything, e.g. because of a `merge=add` field or a misbehaving computed
+// default, Build fails with ErrNotIdempotent listing the changed fields.
+func (p *Processor[T]) WithVerifyIdempotent() *Processor[T] {
+	p.builder.verifyIdempotent = true
+	return p
+}
+
+// WithConflictingDefaultsError enables a check that makes Build fail with ErrConflictingDefaults if multiple defaults
+// provide different values for the same field. Without it, the precedence rules silently pick one of them. The check
+// analyzes the registered defaults before the data-structure is loaded; defaults registered for the same type as well
+// as defaults nested in the defaults of outer types, e.g. via embedding, are compared with each other.
+func (p *Processor[T]) WithConflictingDefaultsError() *Processor[T] {
+	p.builder.checkConflicts = true
+	return p
+}
+
+// Clone returns an independent copy of the processor. The registered defaults are copied, so adding defaults to the
+// clone doesn't affect the original and vice versa. Functions like transformers and validators are shared by
+// reference.
+//
+//	base := konfetty.FromStruct(cfg).WithDefaults(defaults)
+//	a := base.Clone().WithDefaults(tenantADefaults)
+//	b := base.Clone().WithDefaults(tenantBDefaults)
+func (p *Processor[T]) Clone() *Processor[T] {
+	return &Processor[T]{
+		builder: p.builder.clone(),
+	}
+}
+
 // Build processes the data-structure, applying defaults, transformations, and validations. It returns the final
 // struct or an error if any step fails.
 func (p *Processor[T]) Build() (*T, error) {
-	return p.builder.build()
+	return p.BuildContext(context.Background())
+}
+
+// BuildContext is like Build, but passes the context to the provider if it implements ContextProvider.
+func (p *Processor[T]) BuildContext(ctx context.Context) (*T, error) {
+	return withTimeout(ctx, p.builder.timeout, p.builder.build)
+}
+
+// Must returns the result of a build or panics if the build failed. It simplifies initialization code where a broken
+// config is fatal anyway.
+//
+//	cfg := konfetty.Must(konfetty.FromStruct(&MyConfig{}).WithDefaults(defaults).Build())
+func Must[T any](cfg *T, err error) *T {
+	if err != nil {
+		panic(fmt.Errorf("konfetty: %w", err))
+	}
+
+	return cfg
+}
+
+// BuildFrozen is like Build, but returns a deep copy of the processed data-structure that doesn't share any pointers,
+// slices or maps with state held elsewhere. Results of Build may share data with the struct passed to FromStruct,
+// unless WithDeepCopy is used, with the values returned by loaders and providers, e.g. a provider returning the same
+// slice on every load, and with values assigned by transformers. They never share data with registered defaults or
+// the fallback, which are copied. Results of BuildFrozen can be handed out and mutated freely without affecting
+// providers, the processor or other results. Only unexported struct fields, which can't be set via reflection, are
+// copied shallowly.
+func (p *Processor[T]) BuildFrozen() (*T, error) {
+	return withTimeout(context.Background(), p.builder.timeout, p.builder.buildFrozen)
+}
+
+// BuildWithBeforeAfter is like Build, but additionally returns a deep copy of the data-structure as it was loaded,
+// before any processing happened. This is useful for showing users exactly what konfetty changed. The first result is
+// the loaded data-structure, the second one the processed data-structure.
+//
+//	before, after, err := processor.BuildWithBeforeAfter()
+func (p *Processor[T]) BuildWithBeforeAfter() (*T, *T, error) {
+	var before *T
+	after, err := withTimeout(context.Background(), p.builder.timeout, func(ctx context.Context) (*T, error) {
+		loaded, processed, err := p.builder.buildWithBeforeAfter(ctx)
+		before = loaded
+		return processed, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return before, after, nil
+}
+
+// BuildWithReport is like Build, but additionally returns a report of the changes made by the defaults. For every
+// value set by a default, it lists the value's path, its old and new value and which of the registered defaults
+// supplied it. This is useful for debugging overlapping defaults. The report's stats summarize the defaulting stage,
+// e.g. how many of the registered defaults were never applied. Its warnings list non-fatal issues, like defaults that
+// were never applied, defaults skipped for unexported fields and interpolation tokens left unresolved.
+//
+//	cfg, report, err := processor.BuildWithReport()
+//	for _, change := range report.Changes {
+//		fmt.Println(change)
+//	}
+func (p *Processor[T]) BuildWithReport() (*T, *Report, error) {
+	var report *Report
+	cfg, err := withTimeout(context.Background(), p.builder.timeout, func(ctx context.Context) (*T, error) {
+		processed, r, err := p.builder.buildWithReport(ctx)
+		report = r
+		return processed, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, report, nil
+}
+
+func (b *Builder[T]) clone() *Builder[T] {
+	clone := *b
+	clone.transformers = append([]func(*T) error(nil), b.transformers...)
+	clone.hooks = slices.Clone(b.hooks)
+	clone.interfaceDefaults = slices.Clone(b.interfaceDefaults)
+	clone.normalizers = slices.Clone(b.normalizers)
+	clone.concretes = slices.Clone(b.concretes)
+	clone.onLoaded = slices.Clone(b.onLoaded)
+	clone.stages = append([]Stage(nil), b.stages...)
+	clone.profiles = b.profiles.clone()
+	clone.zeroFuncs = maps.Clone(b.zeroFuncs)
+	clone.overridden = maps.Clone(b.overridden)
+
+	clone.scoped = make([]scopedDefaults, len(b.scoped))
+	for i, scope := range b.scoped {
+		clone.scoped[i] = scope
+		clone.scoped[i].defaults = make(map[reflect.Type][]any, len(scope.defaults))
+		for t, values := range scope.defaults {
+			clone.scoped[i].defaults[t] = append([]any(nil), values...)
+		}
+	}
+
+	clone.mapDefaults = make([]pathMapDefaults, len(b.mapDefaults))
+	for i, md := range b.mapDefaults {
+		clone.mapDefaults[i] = md
+		clone.mapDefaults[i].values = maps.Clone(md.values)
+	}
+
+	clone.validators = append([]validator[T](nil), b.validators...)
+	clone.errs = append([]error(nil), b.errs...)
+
+	if b.defaults != nil {
+		clone.defaults = make(map[reflect.Type][]any, len(b.defaults))
+		for t, values := range b.defaults {
+			clone.defaults[t] = append([]any(nil), values...)
+		}
+	}
+
+	if b.computed != nil {
+		clone.computed = make(map[reflect.Type][]computedDefault, len(b.computed))
+		for t, values := range b.computed {
+			clone.computed[t] = append([]computedDefault(nil), values...)
+		}
+	}
+
+	return &clone
+}
+
+func (b *Builder[T]) build(ctx context.Context) (*T, error) {
+	unlock := b.lockSource()
+	defer unlock()
+
+	cfg, err := b.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.process(ctx, cfg, nil)
+}
+
+func (b *Builder[T]) buildFrozen(ctx context.Context) (*T, error) {
+	unlock := b.lockSource()
+	defer unlock()
+
+	cfg, err := b.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.process(ctx, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The copy is made while the source is still locked, as the result may share data with it.
+	frozen := deepCopy(result)
+
+	return &frozen, nil
 }
 
-func (b *Builder[T]) build() (*T, error) {
-	cfg, err := b.load()
+func (b *Builder[T]) buildWithBeforeAfter(ctx context.Context) (*T, *T, error) {
+	unlock := b.lockSource()
+	defer unlock()
+
+	cfg, err := b.prepare(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Processing mutates shared slices, maps and pointers in place, so the snapshot has to be a deep copy.
+	before := deepCopy(&cfg)
+
+	after, err := b.process(ctx, cfg, nil)
 	if err != nil {
-		return nil, fmt.Errorf("load: %w", err)
+		return nil, nil, err
+	}
+
+	return &before, after, nil
+}
+
+func (b *Builder[T]) buildWithReport(ctx context.Context) (*T, *Report, error) {
+	unlock := b.lockSource()
+	defer unlock()
+
+	cfg, err := b.prepare(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &Report{}
+
+	result, err := b.process(ctx, cfg, report)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registered := registeredKeys(nil, "", b.registeredDefaults())
+	for _, scope := range b.scoped {
+		registered = registeredKeys(registered, scope.path, scope.defaults)
+	}
+	report.summarize(registered)
+
+	if !b.strict {
+		// In strict mode, unexported fields with defaults fail the build instead.
+		for _, field := range unexportedDefaultFields(reflect.TypeFor[T](), b.registeredDefaults(), b.tags()) {
+			report.warn(WarningUnexportedDefault, field.path, field.String())
+		}
+	}
+
+	return result, report, nil
+}
+
+// lockSource locks the data source for the duration of a build, if builds mutate it in place. This is the case for
+// the struct passed to FromStruct, unless the processor works on a deep copy. It returns the func to unlock it again.
+func (b *Builder[T]) lockSource() func() {
+	if b.source.mu == nil || b.deepCopy {
+		return func() {}
 	}
 
-	if err = applyDefaults(&cfg, b.defaults); err != nil {
-		return nil, fmt.Errorf("apply defaults: %w", err)
+	b.source.mu.Lock()
+
+	return b.source.mu.Unlock
+}
+
+// prepare checks the processor's configuration and loads the data-structure from its source.
+func (b *Builder[T]) prepare(ctx context.Context) (T, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		var cfg T
+		return cfg, fmt.Errorf("configure: %w", err)
 	}
 
-	if b.transform != nil {
-		b.transform(&cfg)
+	if b.checkConflicts {
+		if err := findConflicts(b.registeredDefaults(), b.tags()); err != nil {
+			var cfg T
+			return cfg, fmt.Errorf("check defaults: %w", err)
+		}
 	}
 
-	if b.validate != nil {
-		if err = b.validate(&cfg); err != nil {
-			return nil, fmt.Errorf("validate: %w", err)
+	if b.strict {
+		err := findUnexportedDefaults(reflect.TypeFor[T](), b.registeredDefaults(), b.tags())
+		if err == nil {
+			// Global defaults are shared by processors of different types, so they may not occur in this one.
+			err = findUnreachableDefaults(reflect.TypeFor[T](), b.profiles.merge(b.defaults), b.tags())
+		}
+		if err != nil {
+			var cfg T
+			return cfg, fmt.Errorf("check defaults: %w", err)
+		}
+	}
+
+	var cfg T
+	err := b.guard("load", func() error {
+		var loadErr error
+		if cfg, loadErr = b.load(ctx); loadErr != nil {
+			return fmt.Errorf("load: %w", loadErr)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return cfg, err
+	}
+
+	for _, hook := range b.onLoaded {
+		if err = checkContext(ctx, "on loaded hook"); err != nil {
+			return cfg, err
+		}
+
+		err = b.guard("on loaded hook", func() error {
+			hook(&cfg)
+			return nil
+		})
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// process runs the processing pipeline on the loaded data-structure. If report is set, the changes made by the
+// defaults are recorded in it. Once the context is done, no further step is started.
+func (b *Builder[T]) process(ctx context.Context, cfg T, report *Report) (*T, error) {
+	var original *T
+	if b.needsOriginal() {
+		snapshot := deepCopy(&cfg)
+		original = &snapshot
+	}
+
+	if b.deepCopy {
+		cfg = deepCopy(&cfg)
+	}
+
+	for _, stage := range b.pipeline() {
+		if err := checkContext(ctx, stage.String()); err != nil {
+			return nil, err
+		}
+
+		err := b.guard(stage.String(), func() error {
+			return b.runStage(stage, &cfg, original, report)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if b.resolveLazies {
+		if err := checkContext(ctx, "resolve lazies"); err != nil {
+			return nil, err
+		}
+
+		err := b.guard("resolve lazies", func() error {
+			resolveLazies(&cfg)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, hook := range b.hooks {
+		if err := checkContext(ctx, "post-validate hook"); err != nil {
+			return nil, err
+		}
+
+		err := b.guard("post-validate hook", func() error {
+			hook(&cfg)
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	return &cfg, nil
 }
 
-func (b *Builder[T]) load() (T, error) {
+// needsOriginal reports whether a validator needs a copy of the data-structure as it was loaded.
+func (b *Builder[T]) needsOriginal() bool {
+	for _, v := range b.validators {
+		if v.diffFn != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyIdempotence applies the defaults to a copy of the defaulted config and returns ErrNotIdempotent if that
+// changes anything.
+func (b *Builder[T]) verifyIdempotence(cfg *T) error {
+	second := deepCopy(cfg)
+	if err := b.defaulter().apply(&second); err != nil {
+		return err
+	}
+
+	changes := diff(reflect.ValueOf(cfg).Elem(), reflect.ValueOf(&second).Elem(), b.tags())
+	if len(changes) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.path)
+	}
+
+	return fmt.Errorf("%w: a second pass changed %s", ErrNotIdempotent, strings.Join(paths, ", "))
+}
+
+func (b *Builder[T]) tags() tagResolver {
+	return tagResolver{keys: b.tagKeys, cache: b.typeCache, pathTag: b.pathTag}
+}
+
+func (b *Builder[T]) defaulter() *defaulter {
+	return &defaulter{
+		defaults:     b.registeredDefaults(),
+		computed:     b.computed,
+		catchAll:     b.catchAll,
+		tags:         b.tags(),
+		copyPointers: b.deepCopy,
+		zeroFuncs:    b.zeroFuncs,
+		tagDefaults:  b.tagDefaults,
+		emptyAsUnset: b.emptyAsUnset,
+		foldMapKeys:  b.foldMapKeys,
+		maxDepth:     b.maxDepth,
+
+		interfaceDefaults: b.interfaceDefaults,
+	}
+}
+
+func (b *Builder[T]) load(ctx context.Context) (T, error) {
 	var cfg T
 	var err error
 
 	switch {
+	case b.source.data != nil && b.deepCopy:
+		// Copied before the base is applied, which would otherwise write into the maps and pointers of the source.
+		cfg = deepCopy(b.source.data)
 	case b.source.data != nil:
 		cfg = *b.source.data
 	case b.source.loaderFunc != nil:
@@ -139,13 +1164,20 @@ func (b *Builder[T]) load() (T, error) {
 			return cfg, fmt.Errorf("from loader func: %w", err)
 		}
 	case b.source.provider != nil:
-		cfg, err = b.source.provider.Load()
+		cfg, err = loadProvider(ctx, b.source.provider, b.retry)
 		if err != nil {
 			return cfg, fmt.Errorf("from provider: %w", err)
 		}
+	case len(b.source.providers) > 0:
+		cfg, err = loadProviders(ctx, b.source.providers, b.retry)
+		if err != nil {
+			return cfg, err
+		}
 	default:
-		return cfg, errors.New("no data source provided")
+		return cfg, ErrNoDataSource
 	}
 
+	b.applyBase(&cfg)
+
 	return cfg, nil
 }
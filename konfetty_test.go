@@ -1,8 +1,20 @@
 package konfetty_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/shoenig/test/must"
 
@@ -39,6 +51,69 @@ func TestFromLoaderFunc(t *testing.T) {
 	must.Eq(t, &TestConfig{Name: "Bob", Age: 25, IsAdmin: false}, result)
 }
 
+func TestFromBytes(t *testing.T) {
+	t.Parallel()
+
+	result, err := konfetty.FromBytes[TestConfig]([]byte(`{"Name": "Carol"}`), json.Unmarshal).
+		WithDefaults(TestConfig{Age: 30}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &TestConfig{Name: "Carol", Age: 30}, result)
+
+	_, err = konfetty.FromBytes[TestConfig]([]byte(`{"Name": 42}`), json.Unmarshal).Build()
+	var typeErr *json.UnmarshalTypeError
+	must.ErrorAs(t, err, &typeErr)
+	must.StrHasPrefix(t, "load: ", err.Error())
+
+	_, err = konfetty.FromBytes[TestConfig]([]byte(`{"Name": "Carol"}`), nil).Build()
+	must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+}
+
+func TestFromFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	must.NoError(t, os.WriteFile(path, []byte(`{"Name": "Erin"}`), 0o600))
+
+	result, err := konfetty.FromFile[TestConfig](path, json.Unmarshal).
+		WithDefaults(TestConfig{Age: 30}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &TestConfig{Name: "Erin", Age: 30}, result)
+
+	_, err = konfetty.FromFile[TestConfig](filepath.Join(dir, "missing.json"), json.Unmarshal).Build()
+	must.ErrorIs(t, err, fs.ErrNotExist)
+	must.StrHasPrefix(t, "load: ", err.Error())
+
+	invalid := filepath.Join(dir, "invalid.json")
+	must.NoError(t, os.WriteFile(invalid, []byte(`{"Name": 42}`), 0o600))
+
+	_, err = konfetty.FromFile[TestConfig](invalid, json.Unmarshal).Build()
+	var typeErr *json.UnmarshalTypeError
+	must.ErrorAs(t, err, &typeErr)
+	must.StrContains(t, err.Error(), "invalid.json")
+
+	_, err = konfetty.FromFile[TestConfig](path, nil).Build()
+	must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+}
+
+func TestMust(t *testing.T) {
+	t.Parallel()
+
+	result := konfetty.Must(konfetty.FromStruct(&TestConfig{}).WithDefaults(TestConfig{Name: "Alice"}).Build())
+	must.Eq(t, &TestConfig{Name: "Alice"}, result)
+
+	defer func() {
+		err, ok := recover().(error)
+		must.True(t, ok)
+		must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+	}()
+
+	konfetty.Must(konfetty.FromStruct[TestConfig](nil).Build())
+	t.Fatal("expected Must to panic")
+}
+
 type MockProvider struct {
 	config TestConfig
 	err    error
@@ -48,6 +123,14 @@ func (m *MockProvider) Load() (TestConfig, error) {
 	return m.config, m.err
 }
 
+type StaticProvider[T any] struct {
+	config T
+}
+
+func (s StaticProvider[T]) Load() (T, error) {
+	return s.config, nil
+}
+
 func TestFromProvider(t *testing.T) {
 	t.Parallel()
 
@@ -70,6 +153,99 @@ func TestWithDefaults(t *testing.T) {
 	must.Eq(t, &TestConfig{Name: "Default", Age: 18, IsAdmin: false}, result)
 }
 
+func TestOverrideDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string
+		Port int
+		User string
+	}
+
+	type Config struct {
+		Name     string
+		Database Database
+	}
+
+	result, err := konfetty.FromStruct(&Config{}).
+		WithDefaults(
+			Config{Name: "app"},
+			Database{Host: "localhost", Port: 5432},
+			&Database{User: "admin"},
+		).
+		OverrideDefaults(Database{Host: "db.internal"}, Database{Port: 6543}).
+		Build()
+
+	// The earlier value and pointer defaults are dropped entirely, both defaults of the override are applied.
+	must.NoError(t, err)
+	must.Eq(t, &Config{Name: "app", Database: Database{Host: "db.internal", Port: 6543}}, result)
+}
+
+func TestWithZeroFunc(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Timeout time.Duration
+		Hosts   []string
+		Retries int
+	}
+
+	config := &Config{Timeout: -1, Hosts: []string{}, Retries: 0}
+
+	result, report, err := konfetty.FromStruct(config).
+		WithDefaults(Config{Timeout: 5 * time.Second, Hosts: []string{"localhost"}}, Config{Retries: 3}).
+		WithZeroFunc(reflect.TypeFor[time.Duration](), func(v reflect.Value) bool { return v.Int() < 0 }).
+		WithZeroFunc(reflect.TypeFor[[]string](), func(v reflect.Value) bool { return v.Len() == 0 }).
+		WithZeroFunc(reflect.TypeFor[int](), func(reflect.Value) bool { return false }).
+		BuildWithReport()
+
+	// The negative timeout and empty slice are filled, while the explicit zero is kept.
+	must.NoError(t, err)
+	must.Eq(t, &Config{Timeout: 5 * time.Second, Hosts: []string{"localhost"}}, result)
+	must.Eq[any](t, time.Duration(-1), report.Changes[0].Old)
+
+	// A zero Duration is set by the user under the custom semantics and isn't replaced.
+	result, err = konfetty.FromStruct(&Config{}).
+		WithDefaults(Config{Timeout: 5 * time.Second}).
+		WithZeroFunc(reflect.TypeFor[time.Duration](), func(v reflect.Value) bool { return v.Int() < 0 }).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, 0, result.Timeout)
+}
+
+func TestWithEmptyAsUnset(t *testing.T) {
+	t.Parallel()
+
+	type Pizza struct {
+		Toppings []string
+		Prices   map[string]int
+		Extras   []string
+	}
+
+	defaults := Pizza{
+		Toppings: []string{"mushrooms"},
+		Prices:   map[string]int{"small": 8},
+	}
+
+	newPizza := func() *Pizza {
+		return &Pizza{Toppings: []string{}, Prices: map[string]int{}, Extras: []string{}}
+	}
+
+	result, err := konfetty.FromStruct(newPizza()).WithDefaults(defaults).WithEmptyAsUnset().Build()
+	must.NoError(t, err)
+	must.Eq(t, []string{"mushrooms"}, result.Toppings)
+	must.Eq(t, map[string]int{"small": 8}, result.Prices)
+	// Empty values without a non-empty default are kept.
+	must.NotNil(t, result.Extras)
+	must.SliceEmpty(t, result.Extras)
+
+	// By default, empty slices are kept, while maps still receive the missing keys.
+	result, err = konfetty.FromStruct(newPizza()).WithDefaults(defaults).Build()
+	must.NoError(t, err)
+	must.Eq(t, []string{}, result.Toppings)
+	must.Eq(t, map[string]int{"small": 8}, result.Prices)
+}
+
 func TestWithTransformer(t *testing.T) {
 	t.Parallel()
 
@@ -85,6 +261,42 @@ func TestWithTransformer(t *testing.T) {
 	must.Eq(t, &TestConfig{Name: "Mr. Dave", Age: 21}, result)
 }
 
+func TestWithTransformerE(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Mixed", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&TestConfig{Name: "Dave"}).
+			WithTransformer(func(c *TestConfig) { c.Name = "Mr. " + c.Name }).
+			WithTransformerE(func(c *TestConfig) error {
+				c.Age = len(c.Name)
+				return nil
+			}).
+			WithTransformer(func(c *TestConfig) { c.IsAdmin = c.Age > 5 }).
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Mr. Dave", Age: 8, IsAdmin: true}, result)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		errDerive := errors.New("can't derive age")
+		called := false
+
+		_, err := konfetty.FromStruct(&TestConfig{Name: "Dave"}).
+			WithTransformerE(func(*TestConfig) error { return errDerive }).
+			WithTransformer(func(*TestConfig) { called = true }).
+			Build()
+
+		must.ErrorIs(t, err, errDerive)
+		must.ErrorContains(t, err, "transform: ")
+		must.False(t, called)
+	})
+}
+
 func TestWithValidator(t *testing.T) {
 	t.Parallel()
 
@@ -150,6 +362,16 @@ func TestBuildErrorCases(t *testing.T) {
 		must.ErrorContains(t, err, "provider error")
 	})
 
+	t.Run("NoDataSource", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct[TestConfig](nil).Build()
+		must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+
+		_, err = konfetty.FromProviders[TestConfig]().Build()
+		must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+	})
+
 	t.Run("ValidatorError", func(t *testing.T) {
 		t.Parallel()
 
@@ -164,3 +386,1450 @@ func TestBuildErrorCases(t *testing.T) {
 		must.ErrorContains(t, err, "validator error")
 	})
 }
+
+type parsedLevel int
+
+func (l *parsedLevel) UnmarshalText(text []byte) error {
+	if string(text) != "debug" {
+		return fmt.Errorf("unknown level %q", text)
+	}
+	*l = 1
+
+	return nil
+}
+
+func TestMergeErrors(t *testing.T) {
+	t.Parallel()
+
+	type Logging struct {
+		Level parsedLevel
+	}
+
+	type Config struct {
+		Name    string `konfetty:"merge=add"`
+		Port    int    `default:"http"`
+		Hosts   []string
+		Logging *Logging
+	}
+
+	tests := []struct {
+		name      string
+		processor *konfetty.Processor[Config]
+		wantErrs  []error
+	}{
+		{
+			name:      "TypeMismatch",
+			processor: konfetty.FromStruct(&Config{}).WithFieldDefault("Hosts", "localhost"),
+			wantErrs:  []error{konfetty.ErrTypeMismatch},
+		},
+		{
+			name:      "DefaultParse",
+			processor: konfetty.FromStruct(&Config{Logging: &Logging{}}).WithFieldDefault("Logging.Level", "trace"),
+			wantErrs:  []error{konfetty.ErrDefaultParse},
+		},
+		{
+			name:      "UnsupportedKind",
+			processor: konfetty.FromStruct(&Config{}).WithDefaults(Config{Name: "default"}),
+			wantErrs:  []error{konfetty.ErrUnsupportedDefaultKind, konfetty.ErrInvalidTag},
+		},
+		{
+			name:      "TagParse",
+			processor: konfetty.FromStruct(&Config{}).WithDefaultsFromStructTags(),
+			wantErrs:  []error{konfetty.ErrDefaultParse, konfetty.ErrInvalidTag},
+		},
+	}
+
+	// Valid textual defaults are parsed into the field.
+	result, parseErr := konfetty.FromStruct(&Config{Logging: &Logging{}}).
+		WithFieldDefault("Logging.Level", "debug").
+		Build()
+	must.NoError(t, parseErr)
+	must.Eq(t, 1, result.Logging.Level)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.processor.Build()
+			for _, want := range tt.wantErrs {
+				must.ErrorIs(t, err, want)
+			}
+		})
+	}
+}
+
+func TestWithDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string
+	}
+
+	type Config struct {
+		Items  []Item
+		Labels map[string]string
+		Owner  *Item
+	}
+
+	config := &Config{
+		Items:  []Item{{Name: "first"}, {}},
+		Labels: map[string]string{"env": "prod"},
+		Owner:  &Item{},
+	}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(
+			Item{Name: "default"},
+			Config{Labels: map[string]string{"team": "core"}},
+		).
+		WithDeepCopy().
+		Build()
+
+	must.NoError(t, err)
+	must.Eq(t, []Item{{Name: "first"}, {Name: "default"}}, result.Items)
+	must.Eq(t, map[string]string{"env": "prod", "team": "core"}, result.Labels)
+	must.Eq(t, &Item{Name: "default"}, result.Owner)
+
+	// The caller's struct must be left untouched.
+	must.Eq(t, []Item{{Name: "first"}, {}}, config.Items)
+	must.Eq(t, map[string]string{"env": "prod"}, config.Labels)
+	must.Eq(t, &Item{}, config.Owner)
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name string
+		Age  int
+		Role string
+	}
+
+	validations := 0
+	base := konfetty.FromStruct(&Config{}).
+		WithDefaults(Config{Name: "Base"}).
+		WithValidator(func(_ *Config) error {
+			validations++
+			return nil
+		})
+
+	a := base.Clone().WithDefaults(Config{Age: 30, Role: "admin"})
+	b := base.Clone().WithDefaults(Config{Age: 40})
+
+	resultA, err := a.Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{Name: "Base", Age: 30, Role: "admin"}, resultA)
+
+	resultB, err := b.Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{Name: "Base", Age: 40}, resultB)
+
+	resultBase, err := base.Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{Name: "Base"}, resultBase)
+
+	must.Eq(t, 3, validations)
+}
+
+// TestCloneSiblings checks that options added to processors cloned from a common parent don't leak into each other,
+// even if the parent's slices have spare capacity.
+func TestCloneSiblings(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name string
+	}
+
+	t.Run("Hooks", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		hook := func(name string) func(*Config) {
+			return func(*Config) { calls = append(calls, name) }
+		}
+
+		base := konfetty.FromStruct(&Config{}).
+			WithPostValidateHook(hook("1")).
+			WithPostValidateHook(hook("2")).
+			WithPostValidateHook(hook("3"))
+
+		a := base.Clone().WithPostValidateHook(hook("a"))
+		_ = base.Clone().WithPostValidateHook(hook("b"))
+
+		_, err := a.Build()
+		must.NoError(t, err)
+		must.Eq(t, []string{"1", "2", "3", "a"}, calls)
+	})
+
+	t.Run("OnLoaded", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		hook := func(name string) func(*Config) {
+			return func(*Config) { calls = append(calls, name) }
+		}
+
+		base := konfetty.FromStruct(&Config{}).
+			WithOnLoaded(hook("1")).
+			WithOnLoaded(hook("2")).
+			WithOnLoaded(hook("3"))
+
+		a := base.Clone().WithOnLoaded(hook("a"))
+		_ = base.Clone().WithOnLoaded(hook("b"))
+
+		_, err := a.Build()
+		must.NoError(t, err)
+		must.Eq(t, []string{"1", "2", "3", "a"}, calls)
+	})
+
+	t.Run("ConcreteTypes", func(t *testing.T) {
+		t.Parallel()
+
+		type Home struct {
+			Primary any
+		}
+
+		light := reflect.TypeFor[ConcreteLight]()
+		base := konfetty.FromStruct(&Home{Primary: map[string]any{"type": "thermostat"}}).
+			WithConcreteType("type", "lamp", light).
+			WithConcreteType("type", "spot", light).
+			WithConcreteType("type", "strip", light)
+
+		a := base.Clone().WithConcreteType("type", "thermostat", reflect.TypeFor[ConcreteThermostat]())
+		_ = base.Clone().WithConcreteType("type", "thermostat", light)
+
+		result, err := a.Build()
+		must.NoError(t, err)
+		must.Eq[any](t, ConcreteThermostat{Type: "thermostat"}, result.Primary)
+	})
+
+	t.Run("Normalizers", func(t *testing.T) {
+		t.Parallel()
+
+		base := konfetty.FromStruct(&Config{Name: " Alice "}).
+			WithNormalizer("Name", strings.TrimSpace).
+			WithNormalizer("Name", strings.TrimSpace).
+			WithNormalizer("Name", strings.TrimSpace)
+
+		a := base.Clone().WithNormalizer("Name", strings.ToUpper)
+		_ = base.Clone().WithNormalizer("Name", strings.ToLower)
+
+		result, err := a.Build()
+		must.NoError(t, err)
+		must.Eq(t, "ALICE", result.Name)
+	})
+
+	t.Run("InterfaceDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		base := konfetty.FromStruct(&Zoo{Animals: []Animal{&Dog{}}})
+		base = konfetty.WithInterfaceDefault[Zoo, Animal](base, Dog{Name: "dog"})
+		base = konfetty.WithInterfaceDefault[Zoo, Animal](base, Dog{Name: "dog"})
+		base = konfetty.WithInterfaceDefault[Zoo, Animal](base, Dog{Name: "dog"})
+
+		a := konfetty.WithInterfaceDefault[Zoo, Animal](base.Clone(), Dog{Legs: 4})
+		_ = konfetty.WithInterfaceDefault[Zoo, Animal](base.Clone(), Dog{Legs: 3})
+
+		result, err := a.Build()
+		must.NoError(t, err)
+		must.Eq[Animal](t, &Dog{Name: "dog", Legs: 4}, result.Animals[0])
+	})
+
+	t.Run("MapDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		type Plugin struct {
+			Options map[string]any
+		}
+
+		base := konfetty.FromStruct(&Plugin{}).
+			WithMapDefaults("Options", map[string]any{"retries": 1}).
+			WithMapDefaults("Options", map[string]any{"retries": 2}).
+			WithMapDefaults("Options", map[string]any{"retries": 3})
+
+		a := base.Clone().WithMapDefaults("Options", map[string]any{"timeout": "30s"})
+		_ = base.Clone().WithMapDefaults("Options", map[string]any{"timeout": "1m"})
+
+		result, err := a.Build()
+		must.NoError(t, err)
+		must.MapEq(t, map[string]any{"retries": 1, "timeout": "30s"}, result.Options)
+	})
+}
+
+func TestWithTagPriority(t *testing.T) {
+	t.Parallel()
+
+	type Quota struct {
+		Requests int `cfg:"merge=add"`
+		Storage  int `konfetty:"merge=add" cfg:"merge=fill"`
+		Limit    int `default:"merge=add"`
+	}
+
+	config := &Quota{Requests: 10, Storage: 10, Limit: 10}
+	defaults := Quota{Requests: 5, Storage: 5, Limit: 5}
+
+	result, err := konfetty.FromStruct(config).WithDefaults(defaults).Build()
+	must.NoError(t, err)
+	must.Eq(t, &Quota{Requests: 10, Storage: 15, Limit: 10}, result)
+
+	result, err = konfetty.FromStruct(config).
+		WithDefaults(defaults).
+		WithTagPriority("konfetty", "cfg").
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &Quota{Requests: 15, Storage: 15, Limit: 10}, result)
+
+	result, err = konfetty.FromStruct(config).
+		WithDefaults(defaults).
+		WithTagPriority("cfg", "konfetty", "default").
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &Quota{Requests: 15, Storage: 10, Limit: 15}, result)
+}
+
+type MockContextProvider struct {
+	MockProvider
+}
+
+func (m *MockContextProvider) LoadContext(ctx context.Context) (TestConfig, error) {
+	if err := ctx.Err(); err != nil {
+		return TestConfig{}, err
+	}
+
+	return TestConfig{Name: "Context", Age: 40}, nil
+}
+
+func TestBuildContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PrefersLoadContext", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &MockContextProvider{MockProvider{config: TestConfig{Name: "Plain"}}}
+
+		result, err := konfetty.FromProvider(provider).Build()
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Context", Age: 40}, result)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		provider := &MockContextProvider{}
+
+		_, err := konfetty.FromProvider(provider).BuildContext(ctx)
+		must.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("PlainProvider", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &MockProvider{config: TestConfig{Name: "Plain"}}
+
+		result, err := konfetty.FromProvider(provider).BuildContext(context.Background())
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Plain"}, result)
+	})
+}
+
+func TestWithValidatorWhen(t *testing.T) {
+	t.Parallel()
+
+	type TLSConfig struct {
+		Enabled  bool
+		CertFile string
+	}
+
+	type Config struct {
+		TLS TLSConfig
+	}
+
+	tlsEnabled := func(c *Config) bool { return c.TLS.Enabled }
+	validateTLS := func(c *Config) error {
+		if c.TLS.CertFile == "" {
+			return errors.New("cert file is required")
+		}
+		return nil
+	}
+
+	t.Run("ConditionOff", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithValidatorWhen(tlsEnabled, validateTLS).
+			Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("ConditionOn", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{TLS: TLSConfig{Enabled: true}}).
+			WithValidatorWhen(tlsEnabled, validateTLS).
+			Build()
+		must.ErrorContains(t, err, "cert file is required")
+	})
+
+	t.Run("ConditionOnAndValid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{TLS: TLSConfig{Enabled: true, CertFile: "cert.pem"}}).
+			WithValidatorWhen(tlsEnabled, validateTLS).
+			Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("MixedWithUnconditional", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithValidatorWhen(tlsEnabled, validateTLS).
+			WithValidator(func(_ *Config) error {
+				return errors.New("always fails")
+			}).
+			Build()
+		must.ErrorContains(t, err, "always fails")
+	})
+
+	t.Run("ReplacedValidator", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		record := func(name string) func(*Config) error {
+			return func(_ *Config) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+
+		// WithValidator keeps setting a single validator, while the other validators are kept.
+		_, err := konfetty.FromStruct(&Config{TLS: TLSConfig{Enabled: true}}).
+			WithValidator(record("first")).
+			WithValidatorWhen(tlsEnabled, record("when")).
+			WithValidators(record("added")).
+			WithValidator(record("second")).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, []string{"second", "when", "added"}, calls)
+	})
+}
+
+func TestWithValidatorDiff(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Secret string
+		Hosts  []string
+	}
+
+	requireSecret := func(original, final *Config) error {
+		if original.Secret == "" {
+			return fmt.Errorf("secret %q was supplied by a default", final.Secret)
+		}
+
+		return nil
+	}
+
+	newProcessor := func(config *Config) *konfetty.Processor[Config] {
+		return konfetty.FromStruct(config).
+			WithDefaults(Config{Secret: "changeme", Hosts: []string{"localhost"}}).
+			WithTransformer(func(cfg *Config) { cfg.Hosts = append(cfg.Hosts, "backup") }).
+			WithValidatorDiff(requireSecret).
+			WithValidatorDiff(func(original, final *Config) error {
+				if len(original.Hosts) >= len(final.Hosts) {
+					return errors.New("expected hosts to be added")
+				}
+
+				return nil
+			})
+	}
+
+	result, err := newProcessor(&Config{Secret: "s3cr3t"}).Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{Secret: "s3cr3t", Hosts: []string{"localhost", "backup"}}, result)
+
+	_, err = newProcessor(&Config{}).Build()
+	must.ErrorContains(t, err, `validate: secret "changeme" was supplied by a default`)
+}
+
+func TestWithDeepCopyPointerMapValues(t *testing.T) {
+	t.Parallel()
+
+	type EndpointConfig struct {
+		URL     string
+		Retries int
+	}
+
+	type Config struct {
+		Endpoints map[string]*EndpointConfig
+	}
+
+	shared := &EndpointConfig{}
+	config := &Config{
+		Endpoints: map[string]*EndpointConfig{
+			"a": shared,
+			"b": shared,
+		},
+	}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(EndpointConfig{URL: "http://default", Retries: 3}).
+		WithDeepCopy().
+		Build()
+	must.NoError(t, err)
+
+	result.Endpoints["a"].Retries = 5
+	must.Eq(t, &EndpointConfig{URL: "http://default", Retries: 5}, result.Endpoints["a"])
+	must.Eq(t, &EndpointConfig{URL: "http://default", Retries: 3}, result.Endpoints["b"])
+	must.Eq(t, &EndpointConfig{}, shared)
+}
+
+func TestFromProviders(t *testing.T) {
+	t.Parallel()
+
+	type DatabaseConfig struct {
+		Host string
+		Port int
+	}
+
+	type Config struct {
+		Name     string
+		Debug    bool
+		Database DatabaseConfig
+		Labels   map[string]string
+		Tags     []string
+	}
+
+	base := StaticProvider[Config]{config: Config{
+		Name:     "base",
+		Database: DatabaseConfig{Host: "localhost", Port: 5432},
+		Labels:   map[string]string{"team": "core", "env": "dev"},
+		Tags:     []string{"a", "b"},
+	}}
+
+	overrides := StaticProvider[Config]{config: Config{
+		Debug:    true,
+		Database: DatabaseConfig{Host: "db.prod"},
+		Labels:   map[string]string{"env": "prod"},
+		Tags:     []string{"c"},
+	}}
+
+	result, err := konfetty.FromProviders(base, overrides).
+		WithDefaults(DatabaseConfig{Port: 3306}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{
+		Name:     "base",
+		Debug:    true,
+		Database: DatabaseConfig{Host: "db.prod", Port: 5432},
+		Labels:   map[string]string{"team": "core", "env": "prod"},
+		Tags:     []string{"c"},
+	}, result)
+
+	failing := &MockProvider{err: errors.New("provider error")}
+	_, err = konfetty.FromProviders[TestConfig](&MockProvider{}, failing).Build()
+	must.ErrorContains(t, err, "from provider 1: provider error")
+
+	// Nil providers are skipped.
+	result, err = konfetty.FromProviders(nil, base, nil).Build()
+	must.NoError(t, err)
+	must.Eq(t, "base", result.Name)
+
+	_, err = konfetty.FromProviders[Config](nil).Build()
+	must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+}
+
+func TestWithTypedDefault(t *testing.T) {
+	t.Parallel()
+
+	type DatabaseConfig struct {
+		Host string
+		Port int
+	}
+
+	type Config struct {
+		Name     string
+		Database DatabaseConfig
+	}
+
+	processor := konfetty.FromStruct(&Config{Database: DatabaseConfig{Host: "db"}}).
+		WithDefaults(Config{Name: "Default"})
+	processor = konfetty.WithTypedDefault(processor, DatabaseConfig{Host: "localhost", Port: 5432})
+
+	result, err := processor.Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{Name: "Default", Database: DatabaseConfig{Host: "db", Port: 5432}}, result)
+}
+
+func TestBuildFrozen(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name string
+	}
+
+	type Config struct {
+		Tags    []string
+		Labels  map[string]string
+		Device  *Device
+		Devices []Device
+	}
+
+	t.Run("Provider", func(t *testing.T) {
+		t.Parallel()
+
+		provider := StaticProvider[Config]{config: Config{
+			Tags:   []string{"a"},
+			Labels: map[string]string{"env": "dev"},
+			Device: &Device{Name: "lamp"},
+		}}
+
+		// Build shares the provider's slices, maps and pointers.
+		shared, err := konfetty.FromProvider[Config](provider).Build()
+		must.NoError(t, err)
+		must.True(t, &provider.config.Tags[0] == &shared.Tags[0])
+
+		frozen, err := konfetty.FromProvider[Config](provider).BuildFrozen()
+		must.NoError(t, err)
+
+		frozen.Tags[0] = "changed"
+		frozen.Labels["env"] = "changed"
+		frozen.Device.Name = "changed"
+		must.Eq(t, "a", provider.config.Tags[0])
+		must.Eq(t, "dev", provider.config.Labels["env"])
+		must.Eq(t, "lamp", provider.config.Device.Name)
+	})
+
+	t.Run("FromStruct", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{Devices: []Device{{}}}
+		processor := konfetty.FromStruct(config).WithDefaults(Device{Name: "device"})
+
+		first, err := processor.BuildFrozen()
+		must.NoError(t, err)
+		second, err := processor.BuildFrozen()
+		must.NoError(t, err)
+
+		first.Devices[0].Name = "first"
+		must.Eq(t, "device", second.Devices[0].Name)
+		must.Eq(t, "device", config.Devices[0].Name)
+	})
+}
+
+func TestBuildWithBeforeAfter(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name  string
+		Port  int
+		Hosts []string
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{Name: "app", Hosts: []string{"a", ""}}
+
+		before, after, err := konfetty.FromStruct(config).
+			WithDefaults(Config{Port: 8080}).
+			WithTransformer(func(cfg *Config) {
+				cfg.Name = "transformed"
+				cfg.Hosts[1] = "b"
+			}).
+			BuildWithBeforeAfter()
+
+		must.NoError(t, err)
+		must.Eq(t, &Config{Name: "app", Hosts: []string{"a", ""}}, before)
+		must.Eq(t, &Config{Name: "transformed", Port: 8080, Hosts: []string{"a", "b"}}, after)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		before, after, err := konfetty.FromStruct(&Config{}).
+			WithValidator(func(*Config) error { return errors.New("invalid") }).
+			BuildWithBeforeAfter()
+
+		must.ErrorContains(t, err, "invalid")
+		must.Nil(t, before)
+		must.Nil(t, after)
+	})
+}
+
+func TestWithDefaultsInvalidKinds(t *testing.T) {
+	t.Parallel()
+
+	for _, dv := range []any{42, "default", []string{"a"}, new(int), time.Second, nil} {
+		_, err := konfetty.FromStruct(&TestConfig{}).
+			WithDefaults(TestConfig{Name: "default"}, dv).
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidDefault, must.Sprintf("default %#v", dv))
+	}
+
+	result, err := konfetty.FromStruct(&TestConfig{}).
+		WithDefaults(TestConfig{Name: "default"}, &TestConfig{}, map[string]int{"a": 1}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, "default", result.Name)
+}
+
+func TestNamedScalarInMainPackage(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("builds and runs a separate program")
+	}
+
+	// Types declared in package main have a package path without a domain, just like the ones of the standard library.
+	out, err := exec.Command("go", "run", "./testdata/mainscalar").CombinedOutput()
+	must.NoError(t, err, must.Sprint(string(out)))
+	must.Eq(t, "8080", string(out))
+}
+
+func TestWithDefaultsNamedScalars(t *testing.T) {
+	t.Parallel()
+
+	type Port int
+
+	type Server struct {
+		Name string
+		Port Port
+	}
+
+	type Config struct {
+		Web      Server
+		Admin    Server
+		Fallback *Port
+		Ports    []Port
+		Named    map[string]Port
+	}
+
+	fallback := Port(0)
+	config := &Config{
+		Admin:    Server{Port: 9000},
+		Fallback: &fallback,
+		Ports:    []Port{0, 1},
+		Named:    map[string]Port{"metrics": 0},
+	}
+
+	result, report, err := konfetty.FromStruct(config).
+		WithDefaults(Port(8080), Config{Ports: []Port{80}}).
+		BuildWithReport()
+	must.NoError(t, err)
+
+	must.Eq(t, 8080, result.Web.Port)
+	must.Eq(t, 9000, result.Admin.Port)
+	must.Eq(t, 8080, *result.Fallback)
+	must.Eq(t, []Port{8080, 1}, result.Ports)
+	must.Eq(t, 8080, result.Named["metrics"])
+
+	var changes []string
+	for _, c := range report.Changes {
+		changes = append(changes, c.String())
+	}
+	must.SliceContains(t, changes, "Web.Port: 0 -> 8080 (default #0 of type konfetty_test.Port)")
+
+	// Struct defaults providing a value for the field take precedence over the scalar default.
+	result, err = konfetty.FromStruct(&Config{}).
+		WithDefaults(Port(8080), Server{Port: 443}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, 443, result.Web.Port)
+}
+
+func TestWithVerifyIdempotent(t *testing.T) {
+	t.Parallel()
+
+	type Limits struct {
+		Retries int `konfetty:"merge=add"`
+	}
+
+	type Config struct {
+		Name   string
+		Limits Limits
+	}
+
+	t.Run("Idempotent", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(Config{Name: "default"}).
+			WithVerifyIdempotent().
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, "default", result.Name)
+	})
+
+	t.Run("NotIdempotent", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(Config{Name: "default"}, Limits{Retries: 3}).
+			WithVerifyIdempotent().
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrNotIdempotent)
+		must.ErrorContains(t, err, "Limits.Retries")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(Limits{Retries: 3}).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, 3, result.Limits.Retries)
+	})
+}
+
+func TestWithDefaultsFromJSON(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	type Config struct {
+		Name     string   `json:"name"`
+		Database Database `json:"database"`
+	}
+
+	defaults := []byte(`{"name": "default", "database": {"host": "localhost", "port": 5432}}`)
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{Database: Database{Host: "db"}}).
+			WithDefaultsFromJSON(defaults).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, &Config{Name: "default", Database: Database{Host: "db", Port: 5432}}, result)
+	})
+
+	t.Run("CustomUnmarshal", func(t *testing.T) {
+		t.Parallel()
+
+		unmarshal := func(data []byte, v any) error {
+			return json.Unmarshal(bytes.ReplaceAll(data, []byte("5432"), []byte("6543")), v)
+		}
+
+		result, err := konfetty.FromStruct(&Config{}).
+			WithDefaultsFromBytes(defaults, unmarshal).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, 6543, result.Database.Port)
+	})
+
+	t.Run("InvalidDocument", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaultsFromJSON([]byte(`{"name": 42}`)).
+			Build()
+
+		var typeErr *json.UnmarshalTypeError
+		must.ErrorAs(t, err, &typeErr)
+	})
+
+	t.Run("NilUnmarshal", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaultsFromBytes(defaults, nil).
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidDefault)
+	})
+}
+
+func TestWithCatchAllDefault(t *testing.T) {
+	t.Parallel()
+
+	type Light struct {
+		Brightness int
+	}
+
+	type Thermostat struct {
+		Temperature float64
+	}
+
+	type Config struct {
+		Name       string
+		Light      Light
+		Thermostat *Thermostat
+	}
+
+	catchAll := func(t reflect.Type) (any, bool) {
+		switch t {
+		case reflect.TypeOf(Light{}):
+			return Light{Brightness: 50}, true
+		case reflect.TypeOf(Thermostat{}):
+			return &Thermostat{Temperature: 20}, true
+		case reflect.TypeOf(Config{}):
+			return Config{Name: "catch-all"}, true
+		default:
+			return nil, false
+		}
+	}
+
+	t.Run("UnregisteredTypes", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{Thermostat: &Thermostat{}}).
+			WithDefaults(Config{Name: "registered"}).
+			WithCatchAllDefault(catchAll).
+			Build()
+
+		// Registered defaults take precedence, the catch-all only supplies the types without any.
+		must.NoError(t, err)
+		must.Eq(t, &Config{
+			Name:       "registered",
+			Light:      Light{Brightness: 50},
+			Thermostat: &Thermostat{Temperature: 20},
+		}, result)
+	})
+
+	t.Run("MismatchedType", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithCatchAllDefault(func(reflect.Type) (any, bool) { return Light{}, true }).
+			Build()
+
+		must.ErrorIs(t, err, konfetty.ErrInvalidDefault)
+	})
+}
+
+type skippedHandle struct {
+	DSN string
+}
+
+func (h *skippedHandle) Validate() error {
+	return errors.New("must not be validated")
+}
+
+func TestSkipTag(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name    string
+		Secret  string         `konfetty:"-"`
+		Handle  *skippedHandle `konfetty:"-"`
+		Primary skippedHandle  `konfetty:"-"`
+	}
+
+	handle := &skippedHandle{}
+	config := &Config{Secret: "p4ss{Name}", Handle: handle}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(
+			Config{Secret: "default", Primary: skippedHandle{DSN: "config"}},
+			skippedHandle{DSN: "default"},
+		).
+		WithInterpolation().
+		Build()
+
+	must.NoError(t, err)
+	must.Eq(t, "p4ss{Name}", result.Secret)
+	must.True(t, result.Handle == handle)
+	must.Eq(t, "", handle.DSN)
+	must.Eq(t, skippedHandle{}, result.Primary)
+
+	must.StrContains(t, konfetty.ExplainDefault(config, "Secret", Config{Secret: "default"}), "excluded")
+}
+
+func TestWithTypeCache(t *testing.T) {
+	t.Parallel()
+
+	type Quota struct {
+		Requests int    `cfg:"merge=add"`
+		Slug     string `konfetty:"pattern=^[a-z]+$"`
+	}
+
+	type Config struct {
+		Quotas []Quota
+	}
+
+	processor := konfetty.FromLoaderFunc(func() (Config, error) {
+		return Config{Quotas: []Quota{{Requests: 10}, {}}}, nil
+	}).
+		WithDefaults(Quota{Requests: 5, Slug: "default"}).
+		WithTypeCache()
+
+	for range 2 {
+		result, err := processor.Build()
+		must.NoError(t, err)
+		must.Eq(t, &Config{Quotas: []Quota{{Requests: 10, Slug: "default"}, {Requests: 5, Slug: "default"}}}, result)
+	}
+
+	// Changing the tag keys invalidates the cached tags.
+	result, err := processor.WithTagPriority("cfg", "konfetty").Build()
+	must.NoError(t, err)
+	must.Eq(t, &Config{Quotas: []Quota{{Requests: 15, Slug: "default"}, {Requests: 5, Slug: "default"}}}, result)
+}
+
+func BenchmarkBuild(b *testing.B) {
+	type Device struct {
+		Name    string `konfetty:"pattern=^[a-z-]+$"`
+		Enabled bool
+		Retries int
+		Labels  map[string]string
+	}
+
+	type Room struct {
+		Name    string
+		Devices []Device
+	}
+
+	type Config struct {
+		Rooms []Room
+	}
+
+	load := func() (Config, error) {
+		rooms := make([]Room, 20)
+		for i := range rooms {
+			rooms[i].Devices = make([]Device, 50)
+		}
+
+		return Config{Rooms: rooms}, nil
+	}
+
+	benchmarks := []struct {
+		name      string
+		processor *konfetty.Processor[Config]
+	}{
+		{name: "NoCache", processor: konfetty.FromLoaderFunc(load)},
+		{name: "TypeCache", processor: konfetty.FromLoaderFunc(load).WithTypeCache()},
+	}
+
+	for _, bm := range benchmarks {
+		bm.processor.WithDefaults(
+			Room{Name: "room"},
+			Device{Name: "device", Enabled: true, Retries: 3, Labels: map[string]string{"a": "b"}},
+		)
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for range b.N {
+				if _, err := bm.processor.Build(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultsNotModifiedByResults(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		N int
+	}
+
+	type Config struct {
+		Items  map[string]*Item
+		Nested map[string]map[string]int
+	}
+
+	def := Config{
+		Items:  map[string]*Item{"a": {N: 1}},
+		Nested: map[string]map[string]int{"a": {"x": 1}},
+	}
+
+	// The maps are set, so the entries of the default are merged into them.
+	result, err := konfetty.FromStruct(&Config{Items: map[string]*Item{}, Nested: map[string]map[string]int{}}).
+		WithDefaults(def).
+		Build()
+	must.NoError(t, err)
+
+	result.Items["a"].N = 99
+	result.Nested["a"]["x"] = 99
+
+	must.Eq(t, 1, def.Items["a"].N)
+	must.Eq(t, 1, def.Nested["a"]["x"])
+}
+
+func TestConcurrentBuild(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name   string
+		Labels map[string]string
+	}
+
+	type Room struct {
+		Name    string
+		Devices []Device
+	}
+
+	type Config struct {
+		Rooms   []Room
+		Primary *Device
+	}
+
+	defaults := []any{
+		Room{Name: "room"},
+		Device{Name: "device", Labels: map[string]string{"a": "b"}},
+		&Config{Primary: &Device{Name: "primary"}},
+	}
+
+	expected := &Config{
+		Rooms: []Room{
+			{Name: "room", Devices: []Device{{Name: "device", Labels: map[string]string{"a": "b"}}}},
+			{Name: "kitchen"},
+		},
+		Primary: &Device{Name: "primary", Labels: map[string]string{"a": "b"}},
+	}
+
+	newConfig := func() *Config {
+		return &Config{Rooms: []Room{{Devices: []Device{{}}}, {Name: "kitchen"}}}
+	}
+
+	processors := map[string]*konfetty.Processor[Config]{
+		"FromStruct":         konfetty.FromStruct(newConfig()).WithDefaults(defaults...),
+		"FromStructDeepCopy": konfetty.FromStruct(newConfig()).WithDefaults(defaults...).WithDeepCopy(),
+		"FromLoaderFunc": konfetty.FromLoaderFunc(func() (Config, error) { return *newConfig(), nil }).
+			WithDefaults(defaults...).
+			WithTypeCache().
+			WithInterpolation().
+			WithValidator(func(*Config) error { return nil }),
+	}
+
+	for name, processor := range processors {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Results of FromStruct processors without deep copy share data with the struct, which later builds
+			// write to, so they are only compared once all builds are done.
+			results := make([]*Config, 50)
+			errs := make([]error, len(results))
+
+			var wg sync.WaitGroup
+			for i := range results {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i], _, errs[i] = processor.BuildWithReport()
+				}()
+			}
+
+			wg.Wait()
+
+			for i, result := range results {
+				must.NoError(t, errs[i])
+				must.Eq(t, expected, result)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string
+		Port int
+	}
+
+	type Config struct {
+		Name     string
+		Database Database
+	}
+
+	cfg := &Config{Database: Database{Host: "db.internal"}}
+	err := konfetty.ApplyDefaults(cfg, Config{Name: "app"}, Database{Host: "localhost", Port: 5432})
+	must.NoError(t, err)
+	must.Eq(t, &Config{Name: "app", Database: Database{Host: "db.internal", Port: 5432}}, cfg)
+
+	must.ErrorIs(t, konfetty.ApplyDefaults(cfg, "invalid"), konfetty.ErrInvalidDefault)
+	must.ErrorIs(t, konfetty.ApplyDefaults[Config](nil), konfetty.ErrNilConfig)
+}
+
+func TestInterfaceSliceValues(t *testing.T) {
+	t.Parallel()
+
+	// Mirrors the complex example, which stores devices as values, not pointers, in a []any.
+	type BaseDevice struct {
+		Name     string
+		Type     string
+		Enabled  bool
+		Location string
+	}
+
+	type Light struct {
+		BaseDevice
+		Brightness int
+		ColorTemp  int
+	}
+
+	type Thermostat struct {
+		BaseDevice
+		TargetTemp float64
+		Mode       string
+	}
+
+	type Room struct {
+		Name    string
+		Devices []any
+	}
+
+	cfg, err := konfetty.FromStruct(&Room{
+		Name: "Living Room",
+		Devices: []any{
+			Light{BaseDevice: BaseDevice{Name: "Main Light"}, Brightness: 80},
+			Thermostat{BaseDevice: BaseDevice{Name: "AC"}},
+		},
+	}).
+		WithDefaults(
+			BaseDevice{Location: "Unknown"},
+			Light{BaseDevice: BaseDevice{Type: "light"}, Brightness: 50, ColorTemp: 3000},
+			Thermostat{BaseDevice: BaseDevice{Type: "thermostat", Enabled: true}, TargetTemp: 22, Mode: "auto"},
+		).
+		Build()
+	must.NoError(t, err)
+
+	must.Eq(t, []any{
+		Light{
+			BaseDevice: BaseDevice{Name: "Main Light", Type: "light", Location: "Unknown"},
+			Brightness: 80,
+			ColorTemp:  3000,
+		},
+		Thermostat{
+			BaseDevice: BaseDevice{Name: "AC", Type: "thermostat", Enabled: true, Location: "Unknown"},
+			TargetTemp: 22,
+			Mode:       "auto",
+		},
+	}, cfg.Devices)
+}
+
+func TestWithKeyCaseInsensitiveDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Headers map[string]string
+	}
+
+	defaults := Config{Headers: map[string]string{"Timeout": "30s", "Accept": "*/*"}}
+
+	result, err := konfetty.FromStruct(&Config{Headers: map[string]string{"timeout": "5s"}}).
+		WithDefaults(defaults, map[string]string{"ACCEPT": "text/plain", "User-Agent": "konfetty"}).
+		WithKeyCaseInsensitiveDefaults().
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, map[string]string{"timeout": "5s", "Accept": "*/*", "User-Agent": "konfetty"}, result.Headers)
+
+	// By default, keys are compared exactly.
+	result, err = konfetty.FromStruct(&Config{Headers: map[string]string{"timeout": "5s"}}).
+		WithDefaults(defaults).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, map[string]string{"timeout": "5s", "Timeout": "30s", "Accept": "*/*"}, result.Headers)
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name string
+		Next *Node
+	}
+
+	type Config struct {
+		Head *Node
+	}
+
+	// Every node adds two levels, the pointer and the struct it points to.
+	newList := func(length int) *Config {
+		config := &Config{}
+		for range length {
+			config.Head = &Node{Next: config.Head}
+		}
+
+		return config
+	}
+
+	t.Run("Exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(newList(10_000)).
+			WithDefaults(Node{Name: "node"}).
+			WithMaxDepth(100).
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrMaxDepthExceeded)
+		must.ErrorContains(t, err, "the limit is 100")
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		t.Parallel()
+
+		config, err := konfetty.FromStruct(newList(10)).
+			WithDefaults(Node{Name: "node"}).
+			WithMaxDepth(100).
+			Build()
+		must.NoError(t, err)
+
+		for node := config.Head; node != nil; node = node.Next {
+			must.Eq(t, "node", node.Name)
+		}
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(newList(1)).WithMaxDepth(-1).Build()
+		must.ErrorContains(t, err, "max depth: -1 is negative")
+	})
+}
+
+func TestWithDefaultsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	type BaseDevice struct {
+		Name    string
+		Enabled bool
+	}
+
+	type Config struct {
+		Devices []BaseDevice
+	}
+
+	t.Run("Equal", func(t *testing.T) {
+		t.Parallel()
+
+		result, report, err := konfetty.FromStruct(&Config{Devices: []BaseDevice{{}}}).
+			WithDefaults(BaseDevice{Name: "device"}).
+			WithDefaults(BaseDevice{Name: "device"}).
+			WithoutGlobalDefaults().
+			BuildWithReport()
+		must.NoError(t, err)
+		must.Eq(t, []BaseDevice{{Name: "device"}}, result.Devices)
+		must.Eq(t, 1, report.Stats.DefaultsRegistered)
+		must.Eq(t, 1, report.Stats.DefaultsMatched)
+		must.SliceEmpty(t, report.Warnings)
+	})
+
+	t.Run("Different", func(t *testing.T) {
+		t.Parallel()
+
+		// Precedence is kept: the later default wins and the earlier one fills the fields it leaves unset.
+		result, report, err := konfetty.FromStruct(&Config{Devices: []BaseDevice{{}}}).
+			WithDefaults(BaseDevice{Name: "device", Enabled: true}, BaseDevice{Name: "lamp"}).
+			WithDefaults(BaseDevice{Name: "device", Enabled: true}).
+			WithoutGlobalDefaults().
+			BuildWithReport()
+		must.NoError(t, err)
+		must.Eq(t, []BaseDevice{{Name: "device", Enabled: true}}, result.Devices)
+		must.Eq(t, 2, report.Stats.DefaultsRegistered)
+	})
+
+	t.Run("MergeAdd", func(t *testing.T) {
+		t.Parallel()
+
+		type Limits struct {
+			Extra int `konfetty:"merge=add"`
+		}
+
+		type Server struct {
+			Limits Limits
+		}
+
+		// Every default adds to the fields merged with merge=add, so equal defaults are kept.
+		result, err := konfetty.FromStruct(&Server{Limits: Limits{Extra: 1}}).
+			WithDefaults(Server{Limits: Limits{Extra: 5}}, Server{Limits: Limits{Extra: 5}}).
+			WithoutGlobalDefaults().
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, 11, result.Limits.Extra)
+
+		result, err = konfetty.FromStruct(&Server{Limits: Limits{Extra: 1}}).
+			WithDefaults(Server{Limits: Limits{Extra: 5}}, Server{Limits: Limits{Extra: 6}}).
+			WithoutGlobalDefaults().
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, 12, result.Limits.Extra)
+	})
+}
+
+func TestNoDefaultTag(t *testing.T) {
+	t.Parallel()
+
+	type Limits struct {
+		Max int
+	}
+
+	type Server struct {
+		Host    string
+		Port    int    `konfetty:"nodefault"`
+		Limits  Limits `konfetty:"nodefault"`
+		Skipped Limits `konfetty:"-"`
+	}
+
+	type Config struct {
+		Servers []Server
+	}
+
+	result, err := konfetty.FromStruct(&Config{Servers: []Server{{}, {Port: 9090}}}).
+		WithDefaults(
+			Server{Host: "localhost", Port: 8080, Limits: Limits{Max: 1}, Skipped: Limits{Max: 1}},
+			Limits{Max: 10},
+		).
+		Build()
+	must.NoError(t, err)
+
+	// Fields tagged with nodefault aren't filled by the server default, but the values below them are still defaulted,
+	// unlike the ones below fields tagged with "-".
+	must.Eq(t, []Server{
+		{Host: "localhost", Limits: Limits{Max: 10}},
+		{Host: "localhost", Port: 9090, Limits: Limits{Max: 10}},
+	}, result.Servers)
+}
+
+func TestNoDefaultTagNamedScalar(t *testing.T) {
+	t.Parallel()
+
+	type Port int
+
+	type Config struct {
+		Public   Port
+		Internal Port  `konfetty:"nodefault"`
+		Admin    *Port `konfetty:"nodefault"`
+	}
+
+	result, err := konfetty.FromStruct(&Config{}).
+		WithDefaults(Port(80)).
+		Build()
+	must.NoError(t, err)
+
+	// Fields tagged with nodefault don't receive the defaults of their own types either.
+	must.Eq(t, &Config{Public: 80}, result)
+}
+
+func TestDefaultsWithInteriorPointers(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		X int
+	}
+
+	type Config struct {
+		A *Inner
+		B *int
+	}
+
+	inner := &Inner{X: 1}
+	result, err := konfetty.FromStruct(&Config{}).
+		WithDefaults(Config{A: inner, B: &inner.X}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, 1, result.A.X)
+	must.Eq(t, 1, *result.B)
+
+	frozen, err := konfetty.FromStruct(&Config{A: inner, B: &inner.X}).BuildFrozen()
+	must.NoError(t, err)
+	must.Eq(t, 1, *frozen.B)
+}
@@ -0,0 +1,81 @@
+package konfetty
+
+import (
+	"reflect"
+)
+
+// Resolve evaluates a lazily computed value. A nil lazy resolves to the zero value of X.
+//
+//	type Config struct {
+//		Token func() string
+//	}
+//
+//	token := konfetty.Resolve(cfg.Token)
+func Resolve[X any](lazy func() X) X {
+	if lazy == nil {
+		var zero X
+		return zero
+	}
+
+	return lazy()
+}
+
+// resolveLazies walks the config and replaces every non-nil lazy field (a func taking no arguments and returning
+// exactly one value) with a func that returns the value computed once by the original lazy.
+func resolveLazies(config any) {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+
+	resolveLaziesRecursive(v.Elem(), make(map[uintptr]bool))
+}
+
+func resolveLaziesRecursive(v reflect.Value, visited map[uintptr]bool) {
+	//nolint:exhaustive // Only container kinds and funcs are relevant for lazy resolution
+	switch v.Kind() {
+	case reflect.Func:
+		resolveLazy(v)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				resolveLaziesRecursive(v.Field(i), visited)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			resolveLaziesRecursive(v.Index(i), visited)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			resolveLaziesRecursive(elem, visited)
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		resolveLaziesRecursive(v.Elem(), visited)
+	case reflect.Interface:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Ptr {
+			resolveLaziesRecursive(v.Elem(), visited)
+		}
+	default:
+		// Other kinds can't hold lazy values
+	}
+}
+
+func resolveLazy(v reflect.Value) {
+	t := v.Type()
+	if v.IsNil() || !v.CanSet() || t.NumIn() != 0 || t.NumOut() != 1 {
+		return
+	}
+
+	result := v.Call(nil)
+	v.Set(reflect.MakeFunc(t, func(_ []reflect.Value) []reflect.Value {
+		return result
+	}))
+}
@@ -0,0 +1,96 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type LazyConfig struct {
+	Name  string
+	Token func() string
+	Port  func() int
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	must.Eq(t, "token", konfetty.Resolve(func() string { return "token" }))
+	must.Eq(t, 0, konfetty.Resolve[int](nil))
+}
+
+func TestLazyFieldsLeftAlone(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	config := &LazyConfig{
+		Token: func() string {
+			calls++
+			return "user-token"
+		},
+	}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(LazyConfig{
+			Name:  "Default",
+			Token: func() string { return "default-token" },
+			Port:  func() int { return 8080 },
+		}).
+		Build()
+
+	must.NoError(t, err)
+	must.Zero(t, calls)
+	must.Eq(t, "Default", result.Name)
+	must.Eq(t, "user-token", konfetty.Resolve(result.Token))
+	must.Eq(t, 8080, konfetty.Resolve(result.Port))
+	must.Eq(t, 1, calls)
+}
+
+func TestLazyFieldsNilDefault(t *testing.T) {
+	t.Parallel()
+
+	config := &LazyConfig{}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(LazyConfig{Name: "Default"}).
+		Build()
+
+	must.NoError(t, err)
+	must.Nil(t, result.Token)
+	must.Nil(t, result.Port)
+}
+
+func TestWithResolveLazies(t *testing.T) {
+	t.Parallel()
+
+	type Nested struct {
+		Lazies []LazyConfig
+	}
+
+	calls := 0
+	config := &Nested{
+		Lazies: []LazyConfig{
+			{
+				Token: func() string {
+					calls++
+					return "computed"
+				},
+			},
+		},
+	}
+
+	result, err := konfetty.FromStruct(config).
+		WithDefaults(LazyConfig{Port: func() int { return 8080 }}).
+		WithResolveLazies().
+		Build()
+
+	must.NoError(t, err)
+	must.Eq(t, 1, calls)
+
+	must.Eq(t, "computed", result.Lazies[0].Token())
+	must.Eq(t, "computed", result.Lazies[0].Token())
+	must.Eq(t, 8080, result.Lazies[0].Port())
+	must.Eq(t, 1, calls)
+}
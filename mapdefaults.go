@@ -0,0 +1,129 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// pathMapDefaults are key-wise defaults for the loosely typed map at path, see WithMapDefaults.
+type pathMapDefaults struct {
+	path     string
+	segments []pathSegment
+	values   map[string]any
+}
+
+// WithMapDefaults registers key-wise defaults for the map at the given path, e.g. a field of type `any` holding a
+// `map[string]any`, as produced by loosely typed loaders. No type-keyed default matches such maps. Every key missing
+// from the map is added with the default's value; nested `map[string]any` values are merged the same way. Nil maps
+// are allocated and nil interfaces are set to a copy of the defaults.
+//
+// Map defaults are applied at the start of the defaults stage, after the fallback. Values taken from them are copied.
+//
+//	processor.WithMapDefaults("Plugins.Options", map[string]any{
+//		"timeout": "30s",
+//		"retry":   map[string]any{"attempts": 3},
+//	})
+func (p *Processor[T]) WithMapDefaults(path string, values map[string]any) *Processor[T] {
+	segments, err := parsePath(path)
+	if err != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("map defaults: %w", err))
+		return p
+	}
+
+	p.builder.mapDefaults = append(p.builder.mapDefaults, pathMapDefaults{path: path, segments: segments, values: values})
+	return p
+}
+
+// applyPathMapDefaults merges the map defaults into the maps of cfg at their paths.
+func (b *Builder[T]) applyPathMapDefaults(cfg *T, report *Report) error {
+	root := reflect.ValueOf(cfg).Elem()
+	for i, md := range b.mapDefaults {
+		v, err := followPath(root, md.segments)
+		if err != nil {
+			return fmt.Errorf("map defaults for %s: %w", md.path, err)
+		}
+
+		origin := registeredDefault{value: md.values, index: i}
+		if err = mergeLooseMap(v, reflect.ValueOf(md.values), md.path, origin, report); err != nil {
+			return fmt.Errorf("map defaults for %s: %w", md.path, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeLooseMap adds the entries of the map src missing from the map stored in v, which may be wrapped in interfaces
+// and pointers. Nested maps are merged recursively. A nil interface is set to a copy of src.
+func mergeLooseMap(v, src reflect.Value, path string, origin registeredDefault, report *Report) error {
+	if v.Kind() == reflect.Map && v.IsNil() && v.CanSet() {
+		// Nil maps of a concrete type are allocated, so that the defaults can be merged into them.
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	if !indirect(v).IsValid() {
+		if !v.CanSet() {
+			return fmt.Errorf("%w: the nil value can't be set", ErrUnknownPath)
+		}
+
+		value := cloneValue(src, make(map[pointerKey]reflect.Value))
+		if !value.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("%w: defaults of type %s are not assignable to a value of type %s",
+				ErrTypeMismatch, value.Type(), v.Type())
+		}
+
+		v.Set(value)
+		recordChange(report, path, reflect.Value{}, value, origin)
+
+		return nil
+	}
+
+	dst := indirect(v)
+	if dst.Kind() != reflect.Map || dst.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: the value of type %s is not a map with string keys", ErrTypeMismatch, dst.Type())
+	}
+
+	for _, key := range src.MapKeys() {
+		dstKey := key.Convert(dst.Type().Key())
+		keyed := keyPath(path, key)
+
+		if existing := dst.MapIndex(dstKey); existing.IsValid() {
+			// Nested maps are merged in place, all other existing values are kept.
+			nested, nestedSrc := indirect(existing), indirect(src.MapIndex(key))
+			if nested.Kind() == reflect.Map && !nested.IsNil() && nestedSrc.Kind() == reflect.Map {
+				if err := mergeLooseMap(nested, nestedSrc, keyed, origin, report); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		value := cloneValue(src.MapIndex(key), make(map[pointerKey]reflect.Value))
+		if value.Kind() == reflect.Interface {
+			value = value.Elem()
+		}
+
+		if !value.IsValid() {
+			continue
+		}
+
+		if !value.Type().AssignableTo(dst.Type().Elem()) {
+			return fmt.Errorf("%s: %w: default of type %s is not assignable to map values of type %s",
+				keyed, ErrTypeMismatch, value.Type(), dst.Type().Elem())
+		}
+
+		dst.SetMapIndex(dstKey, value)
+		recordChange(report, keyed, reflect.Value{}, value, origin)
+	}
+
+	return nil
+}
+
+// recordChange adds a change made by a default to the report, if there is one.
+func recordChange(report *Report, path string, before, after reflect.Value, origin registeredDefault) {
+	if report == nil {
+		return
+	}
+
+	report.Changes = append(report.Changes, newChange(path, before, after, origin))
+}
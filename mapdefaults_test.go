@@ -0,0 +1,84 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithMapDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Plugin struct {
+		Name    string
+		Options any
+	}
+
+	type Config struct {
+		Plugin  Plugin
+		Extra   any
+		Labels  map[string]string
+		Missing any
+	}
+
+	t.Run("Merge", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			Plugin: Plugin{Options: map[string]any{
+				"timeout": "5s",
+				"retry":   map[string]any{"backoff": "1s"},
+			}},
+			Labels: map[string]string{"env": "prod"},
+		}
+
+		cfg, report, err := konfetty.FromStruct(config).
+			WithMapDefaults("Plugin.Options", map[string]any{
+				"timeout": "30s",
+				"verbose": false,
+				"retry":   map[string]any{"attempts": 3, "backoff": "10s"},
+			}).
+			WithMapDefaults("Labels", map[string]any{"env": "dev", "team": "core"}).
+			WithMapDefaults("Missing", map[string]any{"enabled": true}).
+			BuildWithReport()
+
+		must.NoError(t, err)
+		must.Eq[any](t, map[string]any{
+			"timeout": "5s",
+			"verbose": false,
+			"retry":   map[string]any{"attempts": 3, "backoff": "1s"},
+		}, cfg.Plugin.Options)
+		must.Eq(t, map[string]string{"env": "prod", "team": "core"}, cfg.Labels)
+		must.Eq[any](t, map[string]any{"enabled": true}, cfg.Missing)
+
+		paths := make([]string, 0, len(report.Changes))
+		for _, change := range report.Changes {
+			paths = append(paths, change.Path)
+		}
+		must.SliceContainsAll(t, []string{
+			"Plugin.Options[verbose]", "Plugin.Options[retry][attempts]", "Labels[team]", "Missing",
+		}, paths)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{Extra: "not a map"}).
+			WithMapDefaults("Extra", map[string]any{"key": "value"}).
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrTypeMismatch)
+
+		_, err = konfetty.FromStruct(&Config{}).
+			WithMapDefaults("Labels", map[string]any{"count": 1}).
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrTypeMismatch)
+		must.ErrorContains(t, err, "Labels[count]")
+
+		_, err = konfetty.FromStruct(&Config{}).
+			WithMapDefaults("Unknown", map[string]any{}).
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+	})
+}
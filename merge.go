@@ -0,0 +1,127 @@
+package konfetty
+
+import (
+	"reflect"
+)
+
+// overlay deep-merges src into dst. Non-zero values in src override the values in dst, while zero values in src
+// leave dst untouched. Structs, maps and pointers to structs are merged recursively; all other values, including
+// slices, are replaced as a whole. Pointees of dst are copied before merging, so they are never mutated in place.
+func overlay(dst, src reflect.Value) {
+	if src.IsZero() {
+		return
+	}
+
+	//nolint:exhaustive // Only structs, maps and pointers are merged recursively; other kinds are replaced
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := range src.NumField() {
+			if src.Type().Field(i).IsExported() {
+				overlay(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Map:
+		overlayMap(dst, src)
+	case reflect.Ptr:
+		if dst.IsNil() || src.Elem().Kind() != reflect.Struct {
+			dst.Set(src)
+			return
+		}
+
+		merged := reflect.New(dst.Type().Elem())
+		merged.Elem().Set(dst.Elem())
+		overlay(merged.Elem(), src.Elem())
+		dst.Set(merged)
+	default:
+		dst.Set(src)
+	}
+}
+
+func overlayMap(dst, src reflect.Value) {
+	merged := reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+	for _, key := range dst.MapKeys() {
+		merged.SetMapIndex(key, dst.MapIndex(key))
+	}
+
+	for _, key := range src.MapKeys() {
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		if existing := merged.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+
+		overlay(elem, src.MapIndex(key))
+		merged.SetMapIndex(key, elem)
+	}
+
+	dst.Set(merged)
+}
+
+// underlay deep-merges src into dst, but unlike overlay only fills the values of dst that are unset according to
+// isZero. Set values in dst are kept. Structs, maps and pointers to structs are merged recursively; all other values,
+// including slices, are filled as a whole. Values taken from src are copied, so dst never shares pointers, slices or
+// maps with src. Pointers of src that are already on the current path aren't followed again, which breaks cycles.
+func underlay(dst, src reflect.Value, isZero func(reflect.Value) bool, tags tagResolver) {
+	u := &underlayer{isZero: isZero, tags: tags, visited: make(map[pointerKey]bool)}
+	u.merge(dst, src)
+}
+
+// underlayer holds the state of a single underlay call.
+type underlayer struct {
+	isZero func(reflect.Value) bool
+
+	// tags resolves the struct tags of merged fields; fields tagged with `konfetty:"-"` are kept as they are.
+	tags tagResolver
+
+	// visited holds the pointers of src on the current path and is used to break cycles.
+	visited map[pointerKey]bool
+}
+
+func (u *underlayer) merge(dst, src reflect.Value) {
+	if src.IsZero() {
+		return
+	}
+
+	if u.isZero(dst) {
+		dst.Set(cloneValue(src, make(map[pointerKey]reflect.Value)))
+		return
+	}
+
+	//nolint:exhaustive // Only structs, maps and pointers are merged recursively; other set values are kept
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := range src.NumField() {
+			if field := src.Type().Field(i); field.IsExported() && !u.tags.skips(field) {
+				u.merge(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Map:
+		u.mergeMap(dst, src)
+	case reflect.Ptr:
+		key := pointerKey{ptr: src.Pointer(), typ: src.Type()}
+		if src.Elem().Kind() != reflect.Struct || u.visited[key] {
+			return
+		}
+
+		u.visited[key] = true
+		defer delete(u.visited, key)
+
+		u.merge(dst.Elem(), src.Elem())
+	default:
+		// Other set values are kept
+	}
+}
+
+func (u *underlayer) mergeMap(dst, src reflect.Value) {
+	for _, key := range src.MapKeys() {
+		existing := dst.MapIndex(key)
+		if !existing.IsValid() {
+			dst.SetMapIndex(key, cloneValue(src.MapIndex(key), make(map[pointerKey]reflect.Value)))
+			continue
+		}
+
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		elem.Set(existing)
+		u.merge(elem, src.MapIndex(key))
+		dst.SetMapIndex(key, elem)
+	}
+}
@@ -0,0 +1,57 @@
+//nolint:testpackage // We want to thoroughly test the underlying merge logic.
+package konfetty
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestOverlay(t *testing.T) {
+	t.Parallel()
+
+	type Endpoint struct {
+		URL     string
+		Retries int
+	}
+
+	type Config struct {
+		Name      string
+		Endpoint  *Endpoint
+		Endpoints map[string]Endpoint
+		Data      any
+		private   string
+	}
+
+	endpoint := &Endpoint{URL: "http://base", Retries: 1}
+	dst := Config{
+		Name:      "base",
+		Endpoint:  endpoint,
+		Endpoints: map[string]Endpoint{"a": {URL: "http://a", Retries: 1}},
+		Data:      "base",
+		private:   "base",
+	}
+	src := Config{
+		Endpoint:  &Endpoint{Retries: 3},
+		Endpoints: map[string]Endpoint{"a": {Retries: 2}, "b": {URL: "http://b"}},
+		Data:      42,
+		private:   "src",
+	}
+
+	baseEndpoints := dst.Endpoints
+	overlay(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
+
+	must.Eq(t, "base", dst.Name)
+	must.Eq(t, &Endpoint{URL: "http://base", Retries: 3}, dst.Endpoint)
+	must.Eq(t, map[string]Endpoint{
+		"a": {URL: "http://a", Retries: 2},
+		"b": {URL: "http://b"},
+	}, dst.Endpoints)
+	must.Eq(t, 42, dst.Data)
+	must.Eq(t, "base", dst.private)
+
+	// The original pointee and map must not be mutated.
+	must.Eq(t, &Endpoint{URL: "http://base", Retries: 1}, endpoint)
+	must.Eq(t, map[string]Endpoint{"a": {URL: "http://a", Retries: 1}}, baseEndpoints)
+}
@@ -0,0 +1,114 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Normalizations that can be applied to string fields via tag options, e.g. `konfetty:"trim,lower"`.
+const (
+	normalizeTrim  = "trim"
+	normalizeLower = "lower"
+	normalizeUpper = "upper"
+)
+
+// pathNormalizer normalizes the string field at path, see WithNormalizer.
+type pathNormalizer struct {
+	path     string
+	segments []pathSegment
+	fn       func(string) string
+}
+
+// WithNormalizer adds a function normalizing the string field at the given path, e.g. strings.TrimSpace. Normalizers
+// run at the start of the transform stage, before the transformers: first the ones declared in struct tags, e.g.
+// `konfetty:"trim,lower"`, which are applied to every string field carrying them, then the ones added with this
+// method, in order.
+//
+//	processor.WithNormalizer("Database.Host", strings.ToLower)
+func (p *Processor[T]) WithNormalizer(path string, fn func(string) string) *Processor[T] {
+	if fn == nil {
+		return p
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("normalizer: %w", err))
+		return p
+	}
+
+	p.builder.normalizers = append(p.builder.normalizers, pathNormalizer{path: path, segments: segments, fn: fn})
+	return p
+}
+
+// runNormalizers applies the normalizations declared in struct tags and the ones added with WithNormalizer to cfg.
+func (b *Builder[T]) runNormalizers(cfg *T) error {
+	if err := normalizeStructure(cfg, b.tags()); err != nil {
+		return err
+	}
+
+	root := reflect.ValueOf(cfg).Elem()
+	for _, n := range b.normalizers {
+		v, err := followPath(root, n.segments)
+		if err != nil {
+			return wrapPath(n.path, err)
+		}
+
+		if v = indirect(v); !v.IsValid() {
+			continue
+		}
+
+		if v.Kind() != reflect.String || !v.CanSet() {
+			return fmt.Errorf("%s: %w: normalizers require a settable string field, but it's of type %s",
+				n.path, ErrUnknownPath, v.Type())
+		}
+
+		v.SetString(n.fn(v.String()))
+	}
+
+	return nil
+}
+
+// normalizeStructure applies the normalizations declared in the konfetty tags of the string fields of config.
+func normalizeStructure(config any, tags tagResolver) error {
+	return traverse(reflect.ValueOf(config), tags, func(v reflect.Value, path string) error {
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+
+		for i, field := range tags.fields(v.Type()) {
+			if !field.IsExported() || len(field.opts.normalize) == 0 {
+				continue
+			}
+
+			fv := v.Field(i)
+			if fv.Kind() != reflect.String {
+				return fmt.Errorf("%s: %w: %s requires a string field, but %s is of kind %s",
+					joinPath(path, tags.fieldName(field.StructField)), ErrInvalidTag,
+					strings.Join(field.opts.normalize, ","), field.Name, fv.Kind())
+			}
+
+			if fv.CanSet() {
+				fv.SetString(normalize(fv.String(), field.opts.normalize))
+			}
+		}
+
+		return nil
+	})
+}
+
+// normalize applies the named normalizations to s in order.
+func normalize(s string, normalizations []string) string {
+	for _, name := range normalizations {
+		switch name {
+		case normalizeTrim:
+			s = strings.TrimSpace(s)
+		case normalizeLower:
+			s = strings.ToLower(s)
+		case normalizeUpper:
+			s = strings.ToUpper(s)
+		}
+	}
+
+	return s
+}
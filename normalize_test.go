@@ -0,0 +1,80 @@
+package konfetty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host   string `konfetty:"trim,lower"`
+		Driver string `konfetty:"upper"`
+		Name   string
+	}
+
+	type Config struct {
+		Mode      string `konfetty:"trim,pattern=^[a-z]*$"`
+		Database  Database
+		Replicas  []*Database
+		Secondary *Database
+	}
+
+	t.Run("Tags", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := konfetty.FromStruct(&Config{
+			Mode:     "  auto\n",
+			Database: Database{Host: " DB.Internal ", Driver: "pgx", Name: " Main "},
+			Replicas: []*Database{{Host: "\tReplica.Internal"}},
+		}).
+			WithDefaults(Database{Driver: " mysql "}).
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, "auto", cfg.Mode)
+		must.Eq(t, Database{Host: "db.internal", Driver: "PGX", Name: " Main "}, cfg.Database)
+		// Defaults are applied before normalizing, so they are normalized, too.
+		must.Eq(t, &Database{Host: "replica.internal", Driver: " MYSQL "}, cfg.Replicas[0])
+	})
+
+	t.Run("Paths", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := konfetty.FromStruct(&Config{
+			Database:  Database{Name: " Main "},
+			Secondary: &Database{Name: "Backup "},
+		}).
+			WithNormalizer("Database.Name", strings.TrimSpace).
+			WithNormalizer("Secondary.Name", strings.TrimSpace).
+			WithNormalizer("Secondary.Name", strings.ToLower).
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, "Main", cfg.Database.Name)
+		must.Eq(t, "backup", cfg.Secondary.Name)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+
+		type Invalid struct {
+			Port int `konfetty:"trim"`
+		}
+
+		_, err := konfetty.FromStruct(&Invalid{}).Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidTag)
+		must.ErrorContains(t, err, "normalize: Port:")
+
+		_, err = konfetty.FromStruct(&Config{}).WithNormalizer("Database.Port", strings.TrimSpace).Build()
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+
+		_, err = konfetty.FromStruct(&Config{}).WithNormalizer("Database", strings.TrimSpace).Build()
+		must.ErrorContains(t, err, "require a settable string field")
+	})
+}
@@ -0,0 +1,96 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// WithParallelValidation runs the validators added with WithValidatorForEach on up to the given number of values
+// concurrently, which speeds up slow validations of many values, e.g. checking the reachability of thousands of
+// devices. The validators have to be safe for concurrent use and must not modify the values they receive. The errors
+// are kept in traversal order, e.g. `Devices[2]` before `Devices[10]`, so the result doesn't depend on the order the
+// validations finish in. A single worker, the default, validates the values one after another.
+func (p *Processor[T]) WithParallelValidation(workers int) *Processor[T] {
+	if workers < 1 {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("parallel validation: %d workers, at least 1 required",
+			workers))
+		return p
+	}
+
+	p.builder.validationWorkers = workers
+
+	return p
+}
+
+// pathError is an error of the value at path.
+type pathError struct {
+	path string
+	err  error
+}
+
+// joinPathErrors joins the errors in order, each prefixed with its path.
+func joinPathErrors(errs []pathError) error {
+	joined := make([]error, 0, len(errs))
+	for _, e := range errs {
+		joined = append(joined, wrapPath(e.path, e.err))
+	}
+
+	return errors.Join(joined...)
+}
+
+// pathValue is a value reached at path.
+type pathValue[V any] struct {
+	path  string
+	value *V
+}
+
+// validateConcurrently calls fn for every value using the given number of workers and returns the errors of the
+// invalid values in the order of the values. The first panic of fn is re-raised on the calling goroutine once all
+// values were validated, so that it can be recovered, see WithRecover.
+func validateConcurrently[V any](values []pathValue[V], fn func(*V) error, workers int) []pathError {
+	results := make([]error, len(values))
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked any
+
+	for range min(workers, len(values)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							panicOnce.Do(func() { panicked = r })
+						}
+					}()
+
+					results[i] = fn(values[i].value)
+				}()
+			}
+		}()
+	}
+
+	for i := range values {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+
+	var errs []pathError
+	for i, err := range results {
+		if err != nil {
+			errs = append(errs, pathError{path: values[i].path, err: err})
+		}
+	}
+
+	return errs
+}
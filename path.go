@@ -0,0 +1,240 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a single step of a field path. It either names a struct field or holds a slice index or map key,
+// written in brackets, e.g. the path `Rooms[1].Devices` consists of the segments `Rooms`, `[1]` and `Devices`.
+type pathSegment struct {
+	name    string
+	key     string
+	isIndex bool
+}
+
+func (s pathSegment) String() string {
+	if s.isIndex {
+		return "[" + s.key + "]"
+	}
+
+	return s.name
+}
+
+// parsePath parses a dotted field path with optional bracketed indices or map keys, e.g. `Rooms[1].Devices[0].Name`.
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		name, rest, hasIndex := strings.Cut(part, "[")
+		if name == "" && (!hasIndex || len(segments) == 0) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+
+		if name != "" {
+			segments = append(segments, pathSegment{name: name})
+		}
+
+		for hasIndex {
+			var key string
+			key, rest, hasIndex = strings.Cut(rest, "]")
+			if !hasIndex {
+				return nil, fmt.Errorf("%w: unclosed bracket in %q", ErrInvalidPath, path)
+			}
+			segments = append(segments, pathSegment{key: key, isIndex: true})
+
+			if rest == "" {
+				break
+			}
+
+			if !strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	return segments, nil
+}
+
+// lookupPath follows the path starting at v and returns the value found. Pointers and interfaces along the way are
+// dereferenced. Values found inside maps aren't addressable.
+func lookupPath(v reflect.Value, path string) (reflect.Value, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	v, err = followPath(v, segments)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return v, nil
+}
+
+// followPath follows the path segments starting at v and returns the value found.
+func followPath(v reflect.Value, segments []pathSegment) (reflect.Value, error) {
+	var err error
+	for _, segment := range segments {
+		v, err = step(v, segment)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// allocPath follows the path segments starting at v like followPath, but allocates the nil pointers to structs it
+// passes through, so that the value found can be set.
+func allocPath(v reflect.Value, segments []pathSegment) (reflect.Value, error) {
+	var err error
+	for _, segment := range segments {
+		for v.Kind() == reflect.Ptr && v.Type().Elem().Kind() != reflect.Interface {
+			if v.IsNil() {
+				if !v.CanSet() || derefType(v.Type()).Kind() != reflect.Struct {
+					break
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		v, err = step(v, segment)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// step resolves a single path segment relative to v.
+func step(v reflect.Value, segment pathSegment) (reflect.Value, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%w: %s is behind a nil value", ErrUnknownPath, segment)
+	}
+
+	if !segment.isIndex {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w: %s is not a struct field", ErrUnknownPath, segment)
+		}
+
+		field, ok := v.Type().FieldByName(segment.name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: no field %s in %s", ErrUnknownPath, segment, v.Type())
+		}
+
+		fv, err := v.FieldByIndexErr(field.Index)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %s is behind a nil embedded pointer", ErrUnknownPath, segment)
+		}
+
+		return fv, nil
+	}
+
+	//nolint:exhaustive // Only slices, arrays and maps can be indexed
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(segment.key)
+		if err != nil || i < 0 || i >= v.Len() {
+			return reflect.Value{}, fmt.Errorf("%w: index %s out of range", ErrUnknownPath, segment)
+		}
+
+		return v.Index(i), nil
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), segment.key)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			return reflect.Value{}, fmt.Errorf("%w: no map key %s", ErrUnknownPath, segment)
+		}
+
+		return elem, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: %s can't index %s", ErrUnknownPath, segment, v.Type())
+	}
+}
+
+// mapKey converts the textual key of a path segment into a value of the map's key type.
+func mapKey(t reflect.Type, key string) (reflect.Value, error) {
+	//nolint:exhaustive // Only string and integer keys can be expressed in a path
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(key).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: invalid map key [%s]", ErrUnknownPath, key)
+		}
+
+		return reflect.ValueOf(i).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(key, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: invalid map key [%s]", ErrUnknownPath, key)
+		}
+
+		return reflect.ValueOf(u).Convert(t), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: unsupported map key type %s", ErrUnknownPath, t)
+	}
+}
+
+// indirect dereferences pointers and interfaces until it reaches a concrete value. It returns the zero Value if it
+// encounters a nil pointer or interface.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// tagPath returns the path of the given segments relative to a value of type t, with the field names read from the
+// path tag, see tagResolver.fieldName. Fields behind interfaces can't be resolved by type and keep their Go names.
+func (r tagResolver) tagPath(t reflect.Type, segments []pathSegment) string {
+	var path string
+	for _, segment := range segments {
+		t = derefType(t)
+
+		if segment.isIndex {
+			path += segment.String()
+			if k := t.Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map {
+				t = t.Elem()
+			}
+
+			continue
+		}
+
+		field, ok := reflect.StructField{}, false
+		if t.Kind() == reflect.Struct {
+			field, ok = t.FieldByName(segment.name)
+		}
+		if !ok {
+			// The value behind an interface can't be resolved by type, so the remaining names are kept.
+			path = joinPath(path, segment.name)
+			t = reflect.TypeFor[any]()
+
+			continue
+		}
+
+		path = joinPath(path, r.fieldName(field))
+		t = field.Type
+	}
+
+	return path
+}
@@ -0,0 +1,77 @@
+//nolint:testpackage // We want to thoroughly test the underlying path logic.
+package konfetty
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+
+	segments, err := parsePath("Rooms[1].Devices[0][key].Name")
+	must.NoError(t, err)
+	must.SliceLen(t, 6, segments)
+	must.True(t, reflect.DeepEqual([]pathSegment{
+		{name: "Rooms"},
+		{key: "1", isIndex: true},
+		{name: "Devices"},
+		{key: "0", isIndex: true},
+		{key: "key", isIndex: true},
+		{name: "Name"},
+	}, segments))
+
+	for _, path := range []string{"", "A..B", "[0]", "A[0", "A[0]B", "A."} {
+		_, err = parsePath(path)
+		must.ErrorIs(t, err, ErrInvalidPath, must.Sprintf("path %q", path))
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name string
+	}
+
+	type Room struct {
+		Devices []any
+		Labels  map[int]string
+	}
+
+	type Config struct {
+		Rooms []*Room
+		Named map[string]Device
+		Empty *Room
+	}
+
+	config := &Config{
+		Rooms: []*Room{{
+			Devices: []any{&Device{Name: "light"}},
+			Labels:  map[int]string{7: "seven"},
+		}},
+		Named: map[string]Device{"main": {Name: "main"}},
+	}
+
+	tests := []struct {
+		path     string
+		expected any
+	}{
+		{path: "Rooms[0].Devices[0].Name", expected: "light"},
+		{path: "Rooms[0].Labels[7]", expected: "seven"},
+		{path: "Named[main].Name", expected: "main"},
+	}
+
+	for _, tt := range tests {
+		v, err := lookupPath(reflect.ValueOf(config), tt.path)
+		must.NoError(t, err)
+		must.Eq(t, tt.expected, v.Interface())
+	}
+
+	for _, path := range []string{"Rooms[1]", "Rooms[x]", "Named[other]", "Empty.Devices", "Unknown", "Named.Name"} {
+		_, err := lookupPath(reflect.ValueOf(config), path)
+		must.ErrorIs(t, err, ErrUnknownPath, must.Sprintf("path %q", path))
+	}
+}
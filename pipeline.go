@@ -0,0 +1,195 @@
+package konfetty
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Stage is a step of the processing pipeline, see WithPipeline.
+type Stage int
+
+const (
+	// StageDefaults applies the fallback and the defaults, including computed defaults and, if enabled, interpolation.
+	StageDefaults Stage = iota + 1
+
+	// StageTransform runs the normalizers, followed by the transformers in the order they were added.
+	StageTransform
+
+	// StageValidate runs the built-in validations followed by the validators in the order they were added.
+	StageValidate
+)
+
+// defaultPipeline is the order of stages used unless WithPipeline is called.
+func defaultPipeline() []Stage {
+	return []Stage{StageDefaults, StageTransform, StageValidate}
+}
+
+func (s Stage) String() string {
+	switch s {
+	case StageDefaults:
+		return "defaults"
+	case StageTransform:
+		return "transform"
+	case StageValidate:
+		return "validate"
+	default:
+		return "Stage(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// WithPipeline sets the order of the processing stages. By default, the defaults are applied first, followed by the
+// transformers and validators. Stages may be omitted or repeated, e.g. to normalize the input before defaulting and
+// to default the sub-structs created by a transformer:
+//
+//	processor.WithPipeline(
+//		konfetty.StageTransform,
+//		konfetty.StageDefaults,
+//		konfetty.StageValidate,
+//	)
+//
+// Calling it without stages restores the default pipeline.
+func (p *Processor[T]) WithPipeline(stages ...Stage) *Processor[T] {
+	for _, stage := range stages {
+		if stage < StageDefaults || stage > StageValidate {
+			p.builder.errs = append(p.builder.errs, fmt.Errorf("pipeline: unknown stage %s", stage))
+			return p
+		}
+	}
+
+	p.builder.stages = append([]Stage(nil), stages...)
+
+	return p
+}
+
+// WithRedefaultAfterTransform applies the defaults a second time after the transformers ran, so that values created by
+// transformers, e.g. a device split into several ones, receive their defaults too. It's a shorthand for a pipeline
+// with a defaults stage following every transform stage that isn't already followed by one, and can be combined with
+// WithPipeline. The second pass only fills values that are still unset, so values set by the first pass or by the
+// transformers are kept.
+func (p *Processor[T]) WithRedefaultAfterTransform() *Processor[T] {
+	p.builder.redefault = true
+	return p
+}
+
+func (b *Builder[T]) pipeline() []Stage {
+	stages := b.stages
+	if len(stages) == 0 {
+		stages = defaultPipeline()
+	}
+
+	if !b.redefault {
+		return stages
+	}
+
+	redefaulted := make([]Stage, 0, 2*len(stages))
+	for i, stage := range stages {
+		redefaulted = append(redefaulted, stage)
+		if stage == StageTransform && (i+1 == len(stages) || stages[i+1] != StageDefaults) {
+			redefaulted = append(redefaulted, StageDefaults)
+		}
+	}
+
+	return redefaulted
+}
+
+// runStage runs a single stage on cfg. The original, i.e. the loaded data-structure, is only set if a validator needs
+// it.
+func (b *Builder[T]) runStage(stage Stage, cfg, original *T, report *Report) error {
+	switch stage {
+	case StageDefaults:
+		return b.runDefaults(cfg, report)
+	case StageTransform:
+		return b.runTransformers(cfg)
+	case StageValidate:
+		return b.runValidators(cfg, original)
+	default:
+		return fmt.Errorf("pipeline: unknown stage %s", stage)
+	}
+}
+
+func (b *Builder[T]) runDefaults(cfg *T, report *Report) error {
+	if err := b.convertConcreteTypes(cfg); err != nil {
+		return fmt.Errorf("apply defaults: %w", err)
+	}
+
+	b.applyFallback(cfg)
+
+	if err := b.applyPathMapDefaults(cfg, report); err != nil {
+		return fmt.Errorf("apply defaults: %w", err)
+	}
+
+	if err := b.applyScopedDefaults(cfg, report); err != nil {
+		return fmt.Errorf("apply defaults: %w", err)
+	}
+
+	d := b.defaulter()
+	d.report = report
+
+	if err := d.apply(cfg); err != nil {
+		return fmt.Errorf("apply defaults: %w", err)
+	}
+
+	if b.verifyIdempotent {
+		if err := b.verifyIdempotence(cfg); err != nil {
+			return fmt.Errorf("apply defaults: %w", err)
+		}
+	}
+
+	if b.interpolate {
+		if err := interpolate(cfg, b.strict, b.tags(), report); err != nil {
+			return fmt.Errorf("interpolate: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Builder[T]) runTransformers(cfg *T) error {
+	if err := b.runNormalizers(cfg); err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+
+	for _, transform := range b.transformers {
+		if transform == nil {
+			continue
+		}
+
+		if err := transform(cfg); err != nil {
+			return fmt.Errorf("transform: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Builder[T]) runValidators(cfg, original *T) error {
+	if err := validateStructure(cfg, b.tags()); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	for _, v := range b.validators {
+		if v.fn == nil && v.diffFn == nil && v.eachFn == nil {
+			continue
+		}
+
+		if v.cond != nil && !v.cond(cfg) {
+			continue
+		}
+
+		var err error
+		switch {
+		case v.fn != nil:
+			err = v.fn(cfg)
+		case v.diffFn != nil:
+			err = v.diffFn(original, cfg)
+		case v.eachFn != nil:
+			err = v.eachFn(cfg, b.tags(), b.validationWorkers)
+		}
+
+		if err != nil {
+			return fmt.Errorf("validate: %w", err)
+		}
+	}
+
+	return nil
+}
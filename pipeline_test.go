@@ -0,0 +1,183 @@
+package konfetty_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithPipeline(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name    string
+		Enabled bool
+	}
+
+	type Config struct {
+		Name    string
+		Devices []Device
+	}
+
+	normalize := func(cfg *Config) { cfg.Name = strings.TrimSpace(cfg.Name) }
+	addDevice := func(cfg *Config) { cfg.Devices = append(cfg.Devices, Device{}) }
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		// Transformers run after defaulting, so the added device doesn't receive defaults.
+		result, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(Device{Name: "device", Enabled: true}).
+			WithTransformer(addDevice).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, []Device{{}}, result.Devices)
+	})
+
+	t.Run("TransformBeforeDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{Name: "   "}).
+			WithDefaults(Config{Name: "default"}).
+			WithTransformer(normalize).
+			WithPipeline(konfetty.StageTransform, konfetty.StageDefaults, konfetty.StageValidate).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, "default", result.Name)
+	})
+
+	t.Run("RepeatedDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{Devices: []Device{{Name: "lamp"}}}).
+			WithDefaults(Device{Name: "device", Enabled: true}).
+			WithTransformer(addDevice).
+			WithPipeline(konfetty.StageDefaults, konfetty.StageTransform, konfetty.StageDefaults, konfetty.StageValidate).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, []Device{{Name: "lamp", Enabled: true}, {Name: "device", Enabled: true}}, result.Devices)
+	})
+
+	t.Run("OmittedStage", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithValidator(func(*Config) error { return errors.New("invalid") }).
+			WithPipeline(konfetty.StageDefaults, konfetty.StageTransform).
+			Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("UnknownStage", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithPipeline(konfetty.StageDefaults, konfetty.Stage(42)).
+			Build()
+		must.ErrorContains(t, err, "unknown stage Stage(42)")
+	})
+}
+
+func TestWithRedefaultAfterTransform(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name    string
+		Enabled bool
+		Room    string
+	}
+
+	type Config struct {
+		Devices []Device
+	}
+
+	// The transformer splits every device into two, the copy only having a name.
+	split := func(cfg *Config) {
+		for _, device := range cfg.Devices {
+			cfg.Devices = append(cfg.Devices, Device{Name: device.Name + " (copy)"})
+		}
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		result, report, err := konfetty.FromStruct(&Config{Devices: []Device{{Name: "lamp", Room: "kitchen"}}}).
+			WithDefaults(Device{Enabled: true, Room: "hall"}).
+			WithTransformer(split).
+			WithRedefaultAfterTransform().
+			BuildWithReport()
+		must.NoError(t, err)
+		must.Eq(t, []Device{
+			{Name: "lamp", Enabled: true, Room: "kitchen"},
+			{Name: "lamp (copy)", Enabled: true, Room: "hall"},
+		}, result.Devices)
+
+		// The second pass only defaults the values the first one didn't reach.
+		paths := make([]string, 0, len(report.Changes))
+		for _, c := range report.Changes {
+			paths = append(paths, c.Path)
+		}
+		must.Eq(t, []string{"Devices[0].Enabled", "Devices[1].Enabled", "Devices[1].Room"}, paths)
+	})
+
+	t.Run("CustomPipeline", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{Devices: []Device{{Name: "lamp"}}}).
+			WithDefaults(Device{Enabled: true}).
+			WithTransformer(split).
+			WithPipeline(konfetty.StageTransform, konfetty.StageValidate).
+			WithRedefaultAfterTransform().
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, []Device{{Name: "lamp", Enabled: true}, {Name: "lamp (copy)", Enabled: true}}, result.Devices)
+	})
+}
+
+func TestNilFunctions(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name  string
+		Port  int
+		Names []string
+	}
+
+	var calls []string
+
+	var nilTransformer func(*Config)
+	var nilTransformerE func(*Config) error
+	var nilValidator func(*Config) error
+	var nilDiff func(original, final *Config) error
+	var nilEach func(*string) error
+	var nilComputed func(*Config) int
+
+	processor := konfetty.FromStruct(&Config{}).
+		WithDefaults(Config{Name: "default"}).
+		WithTransformer(nilTransformer).
+		WithTransformer(func(*Config) { calls = append(calls, "transform") }).
+		WithTransformerE(nilTransformerE).
+		WithTransformerE(func(*Config) error { calls = append(calls, "transformE"); return nil }).
+		WithValidator(nilValidator).
+		WithValidator(func(*Config) error { calls = append(calls, "validate"); return nil }).
+		WithValidatorWhen(nil, nilValidator).
+		WithValidatorWhen(func(*Config) bool { return true }, nilValidator).
+		WithValidatorDiff(nilDiff).
+		WithValidatorDiff(func(_, _ *Config) error { calls = append(calls, "diff"); return nil }).
+		WithZeroFunc(reflect.TypeFor[int](), nil).
+		WithCatchAllDefault(nil)
+	processor = konfetty.WithValidatorForEach(processor, nilEach)
+	processor = konfetty.WithComputedDefault(processor, "Port", nilComputed)
+	processor = konfetty.WithComputedDefault(processor, "Port", func(*Config) int { return 8080 })
+
+	result, err := processor.Build()
+	must.NoError(t, err)
+	must.Eq(t, "default", result.Name)
+	must.Eq(t, 8080, result.Port)
+	must.Eq(t, []string{"transform", "transformE", "validate", "diff"}, calls)
+}
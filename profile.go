@@ -0,0 +1,116 @@
+package konfetty
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// profiles holds defaults that only apply if their profile is active, e.g. per deployment environment.
+type profiles struct {
+	defaults map[string]map[reflect.Type][]any
+
+	// active is the explicitly selected profile, which is also the fallback if envVar is empty or unset.
+	active string
+
+	// envVar is the environment variable the active profile is read from at build time, if set.
+	envVar string
+}
+
+// WithProfileDefaults adds defaults that only apply if the given profile is active, see WithProfile and
+// WithAutoProfile. Profile defaults take precedence over the defaults added via WithDefaults.
+//
+//	processor.
+//		WithDefaults(Config{LogLevel: "info"}).
+//		WithProfileDefaults("dev", Config{LogLevel: "debug"}).
+//		WithProfile("dev")
+func (p *Processor[T]) WithProfileDefaults(profile string, defaultValues ...any) *Processor[T] {
+	if p.builder.profiles.defaults == nil {
+		p.builder.profiles.defaults = make(map[string]map[reflect.Type][]any)
+	}
+
+	profileDefaults := p.builder.profiles.defaults[profile]
+	if profileDefaults == nil {
+		profileDefaults = make(map[reflect.Type][]any)
+		p.builder.profiles.defaults[profile] = profileDefaults
+	}
+
+	for _, dv := range defaultValues {
+		t := reflect.TypeOf(dv)
+		if err := checkDefaultType(t); err != nil {
+			p.builder.errs = append(p.builder.errs, fmt.Errorf("profile %s: %w", profile, err))
+			continue
+		}
+
+		profileDefaults[t] = appendDefault(profileDefaults[t], dv)
+	}
+
+	return p
+}
+
+// WithProfile activates the defaults of the given profile. If WithAutoProfile is used as well, the profile is only
+// used as a fallback for when the environment variable is empty or unset.
+func (p *Processor[T]) WithProfile(profile string) *Processor[T] {
+	p.builder.profiles.active = profile
+	return p
+}
+
+// WithAutoProfile activates the profile named by the given environment variable, e.g. `APP_ENV`. The variable is
+// read when the processor is built. If it's empty or unset, the profile selected via WithProfile is used, if any.
+//
+//	processor.
+//		WithProfileDefaults("production", prodDefaults).
+//		WithProfileDefaults("development", devDefaults).
+//		WithProfile("development").
+//		WithAutoProfile("APP_ENV")
+func (p *Processor[T]) WithAutoProfile(envVar string) *Processor[T] {
+	p.builder.profiles.envVar = envVar
+	return p
+}
+
+// activeProfile returns the name of the active profile, or an empty string if there is none.
+func (ps profiles) activeProfile() string {
+	if ps.envVar != "" {
+		if profile := os.Getenv(ps.envVar); profile != "" {
+			return profile
+		}
+	}
+
+	return ps.active
+}
+
+// merge returns the defaults with the defaults of the active profile added after them.
+func (ps profiles) merge(defaults map[reflect.Type][]any) map[reflect.Type][]any {
+	profileDefaults := ps.defaults[ps.activeProfile()]
+	if len(profileDefaults) == 0 {
+		return defaults
+	}
+
+	merged := make(map[reflect.Type][]any, len(defaults)+len(profileDefaults))
+	for t, values := range defaults {
+		merged[t] = values
+	}
+
+	for t, values := range profileDefaults {
+		merged[t] = append(append([]any(nil), merged[t]...), values...)
+	}
+
+	return merged
+}
+
+func (ps profiles) clone() profiles {
+	clone := ps
+	if ps.defaults == nil {
+		return clone
+	}
+
+	clone.defaults = make(map[string]map[reflect.Type][]any, len(ps.defaults))
+	for profile, profileDefaults := range ps.defaults {
+		clone.defaults[profile] = make(map[reflect.Type][]any, len(profileDefaults))
+		for t, values := range profileDefaults {
+			clone.defaults[profile][t] = append([]any(nil), values...)
+		}
+	}
+
+	return clone
+}
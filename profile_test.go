@@ -0,0 +1,71 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type ProfileConfig struct {
+	LogLevel string
+	Port     int
+	Debug    bool
+}
+
+func newProfileProcessor() *konfetty.Processor[ProfileConfig] {
+	return konfetty.FromStruct(&ProfileConfig{}).
+		WithDefaults(ProfileConfig{LogLevel: "info", Port: 8080}).
+		WithProfileDefaults("development", ProfileConfig{LogLevel: "debug", Debug: true}).
+		WithProfileDefaults("production", ProfileConfig{LogLevel: "warn", Port: 443})
+}
+
+func TestWithProfile(t *testing.T) {
+	t.Parallel()
+
+	result, err := newProfileProcessor().WithProfile("production").Build()
+	must.NoError(t, err)
+	must.Eq(t, &ProfileConfig{LogLevel: "warn", Port: 443}, result)
+
+	result, err = newProfileProcessor().Build()
+	must.NoError(t, err)
+	must.Eq(t, &ProfileConfig{LogLevel: "info", Port: 8080}, result)
+}
+
+func TestWithAutoProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		expected *ProfileConfig
+	}{
+		{
+			name:     "FromEnvironment",
+			env:      "production",
+			expected: &ProfileConfig{LogLevel: "warn", Port: 443},
+		},
+		{
+			name:     "Fallback",
+			env:      "",
+			expected: &ProfileConfig{LogLevel: "debug", Port: 8080, Debug: true},
+		},
+		{
+			name:     "UnknownProfile",
+			env:      "staging",
+			expected: &ProfileConfig{LogLevel: "info", Port: 8080},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.env)
+
+			result, err := newProfileProcessor().
+				WithProfile("development").
+				WithAutoProfile("APP_ENV").
+				Build()
+			must.NoError(t, err)
+			must.Eq(t, tt.expected, result)
+		})
+	}
+}
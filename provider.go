@@ -0,0 +1,105 @@
+package konfetty
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Provider defines an interface for loading structured data.
+type Provider[T any] interface {
+	Load() (T, error)
+}
+
+// ContextProvider defines an optional interface for providers that support cancellation. If a Provider passed to
+// FromProvider also implements ContextProvider, LoadContext is preferred over Load.
+type ContextProvider[T any] interface {
+	LoadContext(ctx context.Context) (T, error)
+}
+
+// maxRetryBackoff is the longest wait between two attempts the doubling backoff grows to.
+const maxRetryBackoff = time.Minute
+
+// retryPolicy controls how often failed provider loads are attempted and how long to wait in between.
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// loadProvider loads from the provider, retrying failed loads according to the retry policy. The wait between
+// attempts starts at the policy's backoff and doubles after every failed attempt, see nextBackoff.
+func loadProvider[T any](ctx context.Context, provider Provider[T], retry retryPolicy) (T, error) {
+	attempts := max(retry.attempts, 1)
+	backoff := retry.backoff
+
+	for attempt := 1; ; attempt++ {
+		cfg, err := loadProviderOnce(ctx, provider)
+		if err == nil {
+			return cfg, nil
+		}
+
+		if attempt == attempts {
+			if attempts > 1 {
+				return cfg, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+			}
+
+			return cfg, err
+		}
+
+		if waitErr := wait(ctx, backoff); waitErr != nil {
+			return cfg, fmt.Errorf("retry cancelled after %d attempts: %w (last error: %w)", attempt, waitErr, err)
+		}
+
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// nextBackoff returns the doubled backoff, capped at maxRetryBackoff, so that it can't overflow for many attempts.
+// Backoffs starting above the cap aren't shortened.
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff <= 0 || backoff >= maxRetryBackoff {
+		return backoff
+	}
+
+	return min(2*backoff, maxRetryBackoff)
+}
+
+// loadProviderOnce loads from the provider, preferring LoadContext if the provider implements ContextProvider.
+func loadProviderOnce[T any](ctx context.Context, provider Provider[T]) (T, error) {
+	if cp, ok := provider.(ContextProvider[T]); ok {
+		return cp.LoadContext(ctx)
+	}
+
+	return provider.Load()
+}
+
+// loadProviders loads from all providers in order and overlays each result onto the previous ones.
+func loadProviders[T any](ctx context.Context, providers []Provider[T], retry retryPolicy) (T, error) {
+	var cfg T
+
+	dst := reflect.ValueOf(&cfg).Elem()
+	for i, provider := range providers {
+		loaded, err := loadProvider(ctx, provider, retry)
+		if err != nil {
+			return cfg, fmt.Errorf("from provider %d: %w", i, err)
+		}
+
+		overlay(dst, reflect.ValueOf(&loaded).Elem())
+	}
+
+	return cfg, nil
+}
+
+// wait blocks for the given duration or until the context is done.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
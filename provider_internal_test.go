@@ -0,0 +1,26 @@
+//nolint:testpackage // We want to test the backoff without waiting for it.
+package konfetty
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestNextBackoff(t *testing.T) {
+	t.Parallel()
+
+	must.Eq(t, 2*time.Second, nextBackoff(time.Second))
+	must.Eq(t, maxRetryBackoff, nextBackoff(45*time.Second))
+	must.Eq(t, time.Hour, nextBackoff(time.Hour))
+	must.Eq(t, 0, nextBackoff(0))
+
+	// The backoff never overflows, no matter how many attempts are made.
+	backoff := time.Millisecond
+	for range 100 {
+		backoff = nextBackoff(backoff)
+		must.Positive(t, backoff)
+	}
+	must.Eq(t, maxRetryBackoff, backoff)
+}
@@ -0,0 +1,96 @@
+package konfetty_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+var errTransient = errors.New("transient error")
+
+type FlakyProvider struct {
+	failures int
+	calls    int
+}
+
+func (f *FlakyProvider) Load() (TestConfig, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return TestConfig{}, errTransient
+	}
+
+	return TestConfig{Name: "Flaky"}, nil
+}
+
+func TestWithProviderRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SucceedsAfterFailures", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &FlakyProvider{failures: 2}
+
+		result, err := konfetty.FromProvider(provider).
+			WithProviderRetry(3, time.Millisecond).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Flaky"}, result)
+		must.Eq(t, 3, provider.calls)
+	})
+
+	t.Run("GivesUp", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &FlakyProvider{failures: 5}
+
+		_, err := konfetty.FromProvider(provider).
+			WithProviderRetry(2, time.Millisecond).
+			Build()
+		must.ErrorIs(t, err, errTransient)
+		must.ErrorContains(t, err, "giving up after 2 attempts")
+		must.Eq(t, 2, provider.calls)
+	})
+
+	t.Run("NoRetryByDefault", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &FlakyProvider{failures: 1}
+
+		_, err := konfetty.FromProvider(provider).Build()
+		must.ErrorIs(t, err, errTransient)
+		must.Eq(t, 1, provider.calls)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		provider := &FlakyProvider{failures: 5}
+
+		_, err := konfetty.FromProvider(provider).
+			WithProviderRetry(5, time.Hour).
+			BuildContext(ctx)
+		must.ErrorIs(t, err, context.DeadlineExceeded)
+		must.ErrorIs(t, err, errTransient)
+		must.Eq(t, 1, provider.calls)
+	})
+
+	t.Run("MultipleProviders", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &FlakyProvider{failures: 1}
+
+		result, err := konfetty.FromProviders[TestConfig](&MockProvider{}, provider).
+			WithProviderRetry(2, time.Millisecond).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Flaky"}, result)
+	})
+}
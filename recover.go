@@ -0,0 +1,38 @@
+package konfetty
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// WithRecover makes Build recover from panics in user-supplied functions, e.g. a transformer dereferencing a nil
+// pointer, and return them as ErrPanic errors instead of crashing the program. The error message includes the stack of
+// the panic. This allows long-running services that reload their config to keep running on the previous one.
+//
+// Panics are recovered while loading the data-structure and while running the processing stages, which covers
+// loaders, providers, computed defaults, catch-all defaults, zero funcs, transformers and validators.
+func (p *Processor[T]) WithRecover() *Processor[T] {
+	p.builder.recoverPanics = true
+	return p
+}
+
+// guard calls fn and, if the processor recovers panics, converts a panic in fn into an ErrPanic error prefixed with
+// the given step.
+func (b *Builder[T]) guard(step string, fn func() error) error {
+	if !b.recoverPanics {
+		return fn()
+	}
+
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%s: %w: %v\n\n%s", step, ErrPanic, r, debug.Stack())
+			}
+		}()
+
+		err = fn()
+	}()
+
+	return err
+}
@@ -0,0 +1,70 @@
+package konfetty_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithRecover(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string
+	}
+
+	type Config struct {
+		Database *Database
+	}
+
+	// The transformer dereferences the nil database pointer.
+	setHost := func(cfg *Config) { cfg.Database.Host = "localhost" }
+
+	t.Run("Transformer", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{}).
+			WithTransformer(setHost).
+			WithRecover().
+			Build()
+		must.Nil(t, result)
+		must.ErrorIs(t, err, konfetty.ErrPanic)
+		must.StrContains(t, err.Error(), "transform: panic: runtime error: invalid memory address or nil pointer dereference")
+		must.StrContains(t, err.Error(), "goroutine")
+	})
+
+	t.Run("Validator", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithValidator(func(*Config) error { panic("boom") }).
+			WithRecover().
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrPanic)
+		must.StrContains(t, err.Error(), "validate: panic: boom")
+	})
+
+	t.Run("Loader", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromLoaderFunc(func() (Config, error) { panic(errors.New("boom")) }).
+			WithRecover().
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrPanic)
+		must.StrContains(t, err.Error(), "load: panic: boom")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			must.NotNil(t, recover())
+		}()
+
+		_, _ = konfetty.FromStruct(&Config{}).WithTransformer(setHost).Build()
+		t.Fatal("expected Build to panic")
+	})
+}
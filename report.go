@@ -0,0 +1,229 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Report describes what the defaulting stage changed while building a data-structure, see BuildWithReport.
+type Report struct {
+	// Changes lists every value set by a default, in the order they were applied.
+	Changes []Change
+
+	// Stats summarizes the defaulting stage, e.g. for exporting it as metrics.
+	Stats Stats
+
+	// Warnings lists the non-fatal issues found during the build, e.g. registered defaults that were never applied.
+	Warnings []Warning
+
+	// matched holds the registered defaults that were applied to at least one value.
+	matched map[defaultKey]bool
+}
+
+// WarningCategory classifies a Warning.
+type WarningCategory int
+
+const (
+	// WarningUnusedDefault reports a registered default that wasn't applied to any value, e.g. because the
+	// data-structure contains no value of its type.
+	WarningUnusedDefault WarningCategory = iota + 1
+
+	// WarningUnexportedDefault reports an unexported field whose type has registered defaults, which are skipped for
+	// the field since it can't be set. In strict mode, this is an ErrUnexportedDefault error instead.
+	WarningUnexportedDefault
+
+	// WarningUnresolvedToken reports an interpolation token referencing an unknown field, which is left as is. In strict
+	// mode, this is an error instead.
+	WarningUnresolvedToken
+)
+
+func (c WarningCategory) String() string {
+	switch c {
+	case WarningUnusedDefault:
+		return "unused default"
+	case WarningUnexportedDefault:
+		return "unexported default"
+	case WarningUnresolvedToken:
+		return "unresolved token"
+	default:
+		return "WarningCategory(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// Warning is a non-fatal issue found during the build, see Report.
+type Warning struct {
+	Category WarningCategory
+
+	// Path is the path of the value the warning refers to, if any.
+	Path string
+
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Path == "" {
+		return fmt.Sprintf("%s: %s", w.Category, w.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", w.Category, w.Path, w.Message)
+}
+
+// warn adds a warning to the report. It's a no-op on a nil report, which makes reporting optional for callers.
+func (r *Report) warn(category WarningCategory, path, message string) {
+	if r == nil {
+		return
+	}
+
+	r.Warnings = append(r.Warnings, Warning{Category: category, Path: path, Message: message})
+}
+
+// Stats are aggregate metrics of the defaulting stage, see Report.
+type Stats struct {
+	// FieldsVisited is the number of struct fields the defaulting stage visited. Fields visited by multiple defaulting
+	// passes, e.g. by scoped defaults and the defaults added with WithDefaults, are counted once per pass.
+	FieldsVisited int
+
+	// FieldsDefaulted is the number of distinct values set by defaults, i.e. the number of distinct paths in Changes.
+	FieldsDefaulted int
+
+	// DefaultsRegistered is the number of registered defaults, including scoped defaults and the ones of active
+	// profiles. Structurally equal defaults of the same type count once. Computed, catch-all and tag defaults aren't
+	// registered by type and aren't counted.
+	DefaultsRegistered int
+
+	// DefaultsMatched is the number of registered defaults that were applied to at least one value, DefaultsUnused the
+	// number of registered defaults that weren't, e.g. because the data-structure contains no value of their type.
+	DefaultsMatched, DefaultsUnused int
+}
+
+// defaultKey identifies a registered default by the scope it was registered for, its registered type and its index
+// among the defaults of that type.
+type defaultKey struct {
+	scope string
+	typ   reflect.Type
+	index int
+}
+
+// match marks the registered default as applied to a value.
+func (r *Report) match(key defaultKey) {
+	if r.matched == nil {
+		r.matched = make(map[defaultKey]bool)
+	}
+
+	r.matched[key] = true
+}
+
+// summarize computes the stats of the report, given the defaults that were registered.
+func (r *Report) summarize(registered []defaultKey) {
+	paths := make(map[string]bool, len(r.Changes))
+	for _, c := range r.Changes {
+		paths[c.Path] = true
+	}
+
+	r.Stats.FieldsDefaulted = len(paths)
+	r.Stats.DefaultsRegistered = len(registered)
+	r.Stats.DefaultsMatched = 0
+
+	for _, key := range registered {
+		if r.matched[key] {
+			r.Stats.DefaultsMatched++
+			continue
+		}
+
+		message := fmt.Sprintf("default #%d of type %s was never applied", key.index, key.typ)
+		if key.scope != "" {
+			message += " within " + key.scope
+		}
+		r.warn(WarningUnusedDefault, "", message)
+	}
+
+	r.Stats.DefaultsUnused = r.Stats.DefaultsRegistered - r.Stats.DefaultsMatched
+}
+
+// registeredKeys returns the keys of the given defaults, registered for the scope, sorted by type to keep the order of
+// warnings deterministic.
+func registeredKeys(keys []defaultKey, scope string, defaults map[reflect.Type][]any) []defaultKey {
+	types := make([]reflect.Type, 0, len(defaults))
+	for t := range defaults {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	for _, t := range types {
+		for i := range defaults[t] {
+			keys = append(keys, defaultKey{scope: scope, typ: t, index: i})
+		}
+	}
+
+	return keys
+}
+
+// Change is a single value set by a default, or a value that differs between two data-structures, see Diff.
+type Change struct {
+	// Path is the path of the changed value, e.g. `Rooms[1].Devices[0].Name`.
+	Path string
+
+	// Old and New are the values before and after the change. Values added to maps have an Old value of nil. Changes
+	// returned by Diff also report added and removed slice elements, with an Old or New value of nil respectively.
+	Old, New any
+
+	// DefaultType is the type of the default that supplied the value, as it was registered, e.g. a pointer type for
+	// defaults registered as pointers. It is nil for computed defaults and values supplied by field defaulters.
+	DefaultType reflect.Type
+
+	// DefaultIndex is the position of the winning default among all defaults registered for DefaultType, in order of
+	// registration. If multiple defaults of a type provide a value for a field, the last registered one wins. It is -1
+	// for defaults that weren't registered, e.g. computed defaults or the ones supplied by a catch-all.
+	DefaultIndex int
+
+	// Tag is the value of the `default` struct tag the value was parsed from, for defaults read from struct tags, see
+	// WithDefaultsFromStructTags.
+	Tag string
+
+	// FieldDefaulter is the type implementing FieldDefaulter whose DefaultFor method supplied the value, for values
+	// supplied by field defaulters.
+	FieldDefaulter reflect.Type
+}
+
+func newChange(path string, before, after reflect.Value, origin registeredDefault) Change {
+	c := Change{
+		Path:           path,
+		DefaultIndex:   origin.index,
+		Tag:            origin.tag,
+		FieldDefaulter: origin.defaulter,
+	}
+
+	if before.IsValid() {
+		c.Old = before.Interface()
+	}
+
+	if after.IsValid() {
+		c.New = after.Interface()
+	}
+
+	if origin.value != nil {
+		c.DefaultType = reflect.TypeOf(origin.value)
+	}
+
+	return c
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Tag != "":
+		return fmt.Sprintf("%s: %v -> %v (default tag %q)", c.Path, c.Old, c.New, c.Tag)
+	case c.FieldDefaulter != nil:
+		return fmt.Sprintf("%s: %v -> %v (field defaulter %s)", c.Path, c.Old, c.New, c.FieldDefaulter)
+	case c.DefaultType == nil && c.DefaultIndex < 0:
+		return fmt.Sprintf("%s: %v -> %v (computed default)", c.Path, c.Old, c.New)
+	case c.DefaultType == nil:
+		// Changes returned by Diff don't stem from a default
+		return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+	case c.DefaultIndex < 0:
+		return fmt.Sprintf("%s: %v -> %v (catch-all default of type %s)", c.Path, c.Old, c.New, c.DefaultType)
+	default:
+		return fmt.Sprintf("%s: %v -> %v (default #%d of type %s)", c.Path, c.Old, c.New, c.DefaultIndex, c.DefaultType)
+	}
+}
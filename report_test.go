@@ -0,0 +1,260 @@
+package konfetty_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestBuildWithReport(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name  string
+		Power int
+	}
+
+	type Config struct {
+		Name    string
+		Age     int
+		Labels  map[string]string
+		Devices []Device
+	}
+
+	t.Run("MultipleDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		result, report, err := konfetty.FromStruct(&Config{Age: 5}).
+			WithDefaults(
+				Config{Name: "First", Age: 10},
+				Config{Name: "Second", Age: 20},
+				Config{Name: "Third", Age: 30},
+			).
+			BuildWithReport()
+
+		must.NoError(t, err)
+		must.Eq(t, "Third", result.Name)
+		must.Eq(t, []konfetty.Change{
+			{Path: "Name", Old: "", New: "Third", DefaultType: reflect.TypeOf(Config{}), DefaultIndex: 2},
+		}, report.Changes)
+		must.Eq(t, "Name:  -> Third (default #2 of type konfetty_test.Config)", report.Changes[0].String())
+	})
+
+	t.Run("NestedAndMaps", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			Labels:  map[string]string{"env": "prod"},
+			Devices: []Device{{Name: "lamp"}, {Power: 5}},
+		}
+
+		_, report, err := konfetty.FromStruct(config).
+			WithDefaults(
+				Config{Labels: map[string]string{"env": "dev", "team": "core"}},
+				&Device{Name: "pointer", Power: 1},
+				Device{Name: "device"},
+			).
+			BuildWithReport()
+
+		must.NoError(t, err)
+		must.Eq(t, []konfetty.Change{
+			{Path: "Labels[team]", New: "core", DefaultType: reflect.TypeOf(Config{}), DefaultIndex: 0},
+			{Path: "Devices[0].Power", Old: 0, New: 1, DefaultType: reflect.TypeOf(&Device{}), DefaultIndex: 0},
+			{Path: "Devices[1].Name", Old: "", New: "device", DefaultType: reflect.TypeOf(Device{}), DefaultIndex: 0},
+		}, report.Changes)
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		t.Parallel()
+
+		type Unused struct {
+			Value string
+		}
+
+		config := &Config{
+			Name:    "home",
+			Devices: []Device{{Name: "lamp"}, {}},
+		}
+
+		_, report, err := konfetty.FromStruct(config).
+			WithDefaults(
+				Config{Age: 1},
+				Device{Name: "device", Power: 1},
+				&Device{Power: 2},
+				Unused{Value: "unused"},
+			).
+			WithScopedDefaults("Devices[1]", Device{Power: 3}).
+			// Global defaults registered by other tests would be counted as unused.
+			WithoutGlobalDefaults().
+			BuildWithReport()
+
+		must.NoError(t, err)
+		must.Eq(t, konfetty.Stats{
+			// 4 fields of the config and 2 of every device, plus the 2 of the scoped device
+			FieldsVisited: 10,
+			// Age, Devices[0].Power, Devices[1].Name and Devices[1].Power
+			FieldsDefaulted:    4,
+			DefaultsRegistered: 5,
+			DefaultsMatched:    4,
+			DefaultsUnused:     1,
+		}, report.Stats)
+	})
+
+	t.Run("ComputedDefault", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(&Config{Name: "home"})
+		konfetty.WithComputedDefault(processor, "Age", func(cfg *Config) int { return len(cfg.Name) })
+
+		_, report, err := processor.BuildWithReport()
+
+		must.NoError(t, err)
+		must.Eq(t, []konfetty.Change{{Path: "Age", Old: 0, New: 4, DefaultIndex: -1}}, report.Changes)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		_, report, err := konfetty.FromStruct[Config](nil).BuildWithReport()
+
+		must.ErrorIs(t, err, konfetty.ErrNoDataSource)
+		must.Nil(t, report)
+	})
+}
+
+func TestWithPathTag(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `json:"host,omitempty"`
+		Port int    `json:"port"`
+		Name string `json:"-" konfetty:"pattern=^[a-z]+$"`
+	}
+
+	type Config struct {
+		Database  Database `json:"database"`
+		Databases []Database
+	}
+
+	t.Run("Report", func(t *testing.T) {
+		t.Parallel()
+
+		_, report, err := konfetty.FromStruct(&Config{Databases: []Database{{Host: "replica"}}}).
+			WithDefaults(Database{Port: 5432, Name: "main"}).
+			WithPathTag("json").
+			BuildWithReport()
+		must.NoError(t, err)
+
+		paths := make([]string, 0, len(report.Changes))
+		for _, change := range report.Changes {
+			paths = append(paths, change.Path)
+		}
+		must.Eq(t, []string{"database.port", "database.Name", "Databases[0].port", "Databases[0].Name"}, paths)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{Database: Database{Name: "Main"}}).
+			WithPathTag("json").
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrPatternMismatch)
+		must.ErrorContains(t, err, "database.Name:")
+	})
+}
+
+func TestDiffConfigs(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name    string
+		Enabled bool
+	}
+
+	type Config struct {
+		Name    string
+		Devices []Device
+		Labels  map[string]string
+	}
+
+	previous := &Config{
+		Name:    "home",
+		Devices: []Device{{Name: "lamp"}, {Name: "fan"}},
+		Labels:  map[string]string{"floor": "1", "zone": "a"},
+	}
+
+	current := &Config{
+		Name:    "home",
+		Devices: []Device{{Name: "lamp", Enabled: true}, {Name: "fan"}, {Name: "heater"}},
+		Labels:  map[string]string{"floor": "2", "room": "kitchen"},
+	}
+
+	changes := konfetty.Diff(previous, current)
+	must.Eq(t, []konfetty.Change{
+		{Path: "Devices[0].Enabled", Old: false, New: true},
+		{Path: "Devices[2]", New: Device{Name: "heater"}},
+		{Path: "Labels[floor]", Old: "1", New: "2"},
+		{Path: "Labels[zone]", Old: "a"},
+		{Path: "Labels[room]", New: "kitchen"},
+	}, changes)
+	must.Eq(t, "Devices[0].Enabled: false -> true", changes[0].String())
+
+	// Removals are reported when diffing in the other direction.
+	changes = konfetty.Diff(current, previous)
+	must.Eq(t, konfetty.Change{Path: "Devices[2]", Old: Device{Name: "heater"}}, changes[1])
+
+	must.SliceEmpty(t, konfetty.Diff(previous, previous))
+}
+
+func TestBuildWithReportWarnings(t *testing.T) {
+	t.Parallel()
+
+	type Unused struct {
+		Value string
+	}
+
+	type Database struct {
+		Host string
+	}
+
+	type Config struct {
+		Name     string
+		Greeting string
+		database Database //nolint:unused // Used for testing unexported fields
+	}
+
+	_, report, err := konfetty.FromStruct(&Config{Name: "home", Greeting: "Hello {Name}, from {Owner}"}).
+		WithDefaults(Database{Host: "localhost"}, Unused{Value: "unused"}).
+		WithInterpolation().
+		// Global defaults registered by other tests would be reported as unused.
+		WithoutGlobalDefaults().
+		BuildWithReport()
+	must.NoError(t, err)
+
+	categories := make([]konfetty.WarningCategory, 0, len(report.Warnings))
+	for _, warning := range report.Warnings {
+		categories = append(categories, warning.Category)
+	}
+	must.Eq(t, []konfetty.WarningCategory{
+		konfetty.WarningUnresolvedToken,
+		konfetty.WarningUnusedDefault,
+		konfetty.WarningUnusedDefault,
+		konfetty.WarningUnexportedDefault,
+	}, categories)
+
+	must.Eq(t, "Greeting", report.Warnings[0].Path)
+	must.StrContains(t, report.Warnings[0].Message, "{Owner}")
+	must.Eq(t, "unused default: default #0 of type konfetty_test.Database was never applied", report.Warnings[1].String())
+	must.Eq(t, "database", report.Warnings[3].Path)
+
+	// In strict mode, the issues are errors instead.
+	_, _, err = konfetty.FromStruct(&Config{Greeting: "{Owner}"}).
+		WithDefaults(Database{Host: "localhost"}).
+		WithInterpolation().
+		WithStrict().
+		BuildWithReport()
+	must.ErrorIs(t, err, konfetty.ErrUnexportedDefault)
+}
@@ -0,0 +1,153 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/nikoksr/konfetty/internal/convert"
+)
+
+// Reset sets the fields at the given paths of cfg back to their zero value, so that a subsequent build applies the
+// defaults to them again. Paths use the same syntax as elsewhere, e.g. `Server.Port` or `Rooms[1].Devices`; a path
+// ending in a map key deletes the entry from the map. Unknown paths, including map keys missing from their map, return
+// an error wrapping ErrUnknownPath, in which case no field is reset.
+//
+//	err := konfetty.Reset(cfg, "Server.Port", "Database")
+func Reset[T any](cfg *T, fields ...string) error {
+	if cfg == nil {
+		return ErrNilConfig
+	}
+
+	root := reflect.ValueOf(cfg).Elem()
+
+	// Resolve all paths first, so that an invalid path doesn't leave the config partially reset.
+	resets := make([]func(), 0, len(fields))
+	for _, field := range fields {
+		reset, err := resetter(root, field)
+		if err != nil {
+			return fmt.Errorf("reset: %w", err)
+		}
+		resets = append(resets, reset)
+	}
+
+	for _, reset := range resets {
+		reset()
+	}
+
+	return nil
+}
+
+// resetter returns a func that resets the value at the path.
+func resetter(root reflect.Value, path string) (func(), error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := followPath(root, segments[:len(segments)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	last := segments[len(segments)-1]
+	if container := indirect(parent); last.isIndex && container.Kind() == reflect.Map {
+		key, keyErr := mapKey(container.Type().Key(), last.key)
+		if keyErr != nil {
+			return nil, fmt.Errorf("%s: %w", path, keyErr)
+		}
+
+		if !container.MapIndex(key).IsValid() {
+			return nil, fmt.Errorf("%s: %w: no map key %s", path, ErrUnknownPath, last)
+		}
+
+		return func() { container.SetMapIndex(key, reflect.Value{}) }, nil
+	}
+
+	v, err := step(parent, last)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if !v.CanSet() {
+		return nil, fmt.Errorf("%s: the value isn't addressable, e.g. because it's stored in a map or interface", path)
+	}
+
+	return func() { v.SetZero() }, nil
+}
+
+// ResetToDefaults sets every field of cfg that has a registered default back to that default, regardless of its
+// current value. Fields without a default, i.e. whose default is the zero value, are kept, so are the fields of
+// nested structs that the default leaves unset. It's the inverse of the usual defaulting, which only fills unset
+// fields, and is useful e.g. for restoring the defaults of a config edited in an admin UI. Defaults take the same
+// precedence as in Build, e.g. the default of an outer struct wins over the default of its field's type. Computed and
+// tag defaults still only fill unset fields. Unlike Build, cfg is mutated in place and neither transformed nor
+// validated.
+//
+//	err := processor.ResetToDefaults(cfg)
+func (p *Processor[T]) ResetToDefaults(cfg *T) error {
+	if cfg == nil {
+		return ErrNilConfig
+	}
+
+	if err := errors.Join(p.builder.errs...); err != nil {
+		return fmt.Errorf("configure: %w", err)
+	}
+
+	d := p.builder.defaulter()
+	d.overwrite = true
+	d.written = make(map[string]bool)
+
+	if err := d.apply(cfg); err != nil {
+		return fmt.Errorf("reset defaults: %w", err)
+	}
+
+	return nil
+}
+
+// overwriteField replaces dst with the default src, unless src is unset or dst was already overwritten by a default
+// of higher precedence. Structs are descended into, so that their fields without a default are kept. Structs that are
+// opaque values, e.g. time.Time, are replaced as a whole.
+func (d *defaulter) overwriteField(dst, src reflect.Value, path string) error {
+	if src.IsZero() || d.isWritten(path) {
+		return nil
+	}
+
+	//nolint:exhaustive // Only structs and pointers to structs are descended into; other kinds are replaced
+	switch src.Kind() {
+	case reflect.Struct:
+		if !convert.IsTextual(dst.Type()) {
+			return d.mergeDefault(dst, src, path)
+		}
+	case reflect.Ptr:
+		if src.Elem().Kind() == reflect.Struct && !convert.IsTextual(src.Elem().Type()) {
+			return d.mergePtrField(dst, src, path)
+		}
+	default:
+		// Other kinds are replaced as a whole
+	}
+
+	before := reflect.New(dst.Type()).Elem()
+	before.Set(dst)
+
+	if err := setField(dst, src); err != nil {
+		return wrapPath(path, err)
+	}
+	d.written[path] = true
+	d.record(path, before, dst)
+
+	return nil
+}
+
+// isWritten reports whether the value at path or one of its parents was overwritten by ResetToDefaults.
+func (d *defaulter) isWritten(path string) bool {
+	for written := range d.written {
+		if written == "" || (strings.HasPrefix(path, written) &&
+			(len(path) == len(written) || path[len(written)] == '.' || path[len(written)] == '[')) {
+			return true
+		}
+	}
+
+	return false
+}
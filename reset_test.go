@@ -0,0 +1,197 @@
+package konfetty_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Host string
+		Port int
+	}
+
+	type Database struct {
+		Name string
+		Port int
+	}
+
+	type Config struct {
+		Server   Server
+		Database *Database
+		Rooms    []Server
+		Labels   map[string]string
+		Named    map[string]Server
+	}
+
+	newConfig := func() *Config {
+		return &Config{
+			Server:   Server{Host: "example.com", Port: 9090},
+			Database: &Database{Name: "app", Port: 6543},
+			Rooms:    []Server{{Host: "kitchen", Port: 1}},
+			Labels:   map[string]string{"env": "prod", "team": "core"},
+			Named:    map[string]Server{"main": {Host: "main"}},
+		}
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		t.Parallel()
+
+		config := newConfig()
+		must.NoError(t, konfetty.Reset(config, "Server.Port", "Database", "Rooms[0].Host", "Labels[env]"))
+		must.Eq(t, Server{Host: "example.com"}, config.Server)
+		must.Nil(t, config.Database)
+		must.Eq(t, []Server{{Port: 1}}, config.Rooms)
+		must.MapEq(t, map[string]string{"team": "core"}, config.Labels)
+	})
+
+	t.Run("DefaultsReapplied", func(t *testing.T) {
+		t.Parallel()
+
+		config := newConfig()
+		must.NoError(t, konfetty.Reset(config, "Server.Port"))
+
+		config, err := konfetty.FromStruct(config).
+			WithDefaults(Server{Host: "localhost", Port: 8080}).
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, Server{Host: "example.com", Port: 8080}, config.Server)
+	})
+
+	t.Run("UnknownPath", func(t *testing.T) {
+		t.Parallel()
+
+		config := newConfig()
+		err := konfetty.Reset(config, "Server.Port", "Server.Missing")
+		must.ErrorIs(t, err, konfetty.ErrUnknownPath)
+		must.ErrorContains(t, err, "Server.Missing")
+		must.Eq(t, 9090, config.Server.Port)
+
+		must.ErrorIs(t, konfetty.Reset(config, "Rooms[3]"), konfetty.ErrUnknownPath)
+		must.ErrorIs(t, konfetty.Reset(config, "Labels[missing]"), konfetty.ErrUnknownPath)
+		must.ErrorIs(t, konfetty.Reset(config, "Server..Port"), konfetty.ErrInvalidPath)
+	})
+
+	t.Run("NotAddressable", func(t *testing.T) {
+		t.Parallel()
+
+		err := konfetty.Reset(newConfig(), "Named[main].Host")
+		must.ErrorContains(t, err, "Named[main].Host: the value isn't addressable")
+	})
+
+	t.Run("NilConfig", func(t *testing.T) {
+		t.Parallel()
+
+		must.ErrorIs(t, konfetty.Reset[Config](nil, "Server"), konfetty.ErrNilConfig)
+	})
+}
+
+func TestResetToDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Host    string
+		Port    int
+		Timeout time.Duration
+	}
+
+	type Config struct {
+		Name    string
+		Server  Server
+		Backup  *Server
+		Started time.Time
+	}
+
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	processor := konfetty.FromStruct(&Config{}).
+		WithDefaults(
+			Server{Host: "localhost", Port: 8080},
+			Config{Started: started},
+		)
+
+	t.Run("Overwrite", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			Name:    "home",
+			Server:  Server{Host: "example.com", Port: 9090, Timeout: time.Second},
+			Backup:  &Server{Host: "backup", Timeout: time.Minute},
+			Started: time.Now(),
+		}
+		must.NoError(t, processor.ResetToDefaults(config))
+
+		// Fields with a default are overwritten, the ones without a default are kept.
+		must.Eq(t, "home", config.Name)
+		must.Eq(t, Server{Host: "localhost", Port: 8080, Timeout: time.Second}, config.Server)
+		must.Eq(t, &Server{Host: "localhost", Port: 8080, Timeout: time.Minute}, config.Backup)
+		must.Eq(t, started, config.Started)
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{}
+		must.NoError(t, processor.ResetToDefaults(config))
+		must.Eq(t, Server{Host: "localhost", Port: 8080}, config.Server)
+		must.Nil(t, config.Backup)
+	})
+
+	t.Run("NilConfig", func(t *testing.T) {
+		t.Parallel()
+
+		must.ErrorIs(t, processor.ResetToDefaults(nil), konfetty.ErrNilConfig)
+	})
+}
+
+func TestResetToDefaultsPrecedence(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		X int
+		Y int
+	}
+
+	type Outer struct {
+		In Inner
+	}
+
+	tests := []struct {
+		name     string
+		defaults []any
+		want     Outer
+	}{
+		{
+			name:     "LaterDefaultOfSameType",
+			defaults: []any{Inner{X: 1, Y: 1}, Inner{X: 2}},
+			want:     Outer{In: Inner{X: 2, Y: 1}},
+		},
+		{
+			name:     "OuterDefault",
+			defaults: []any{Outer{In: Inner{X: 1}}, Inner{X: 2, Y: 2}},
+			want:     Outer{In: Inner{X: 1, Y: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			processor := konfetty.FromStruct(&Outer{}).WithDefaults(tt.defaults...)
+
+			built, err := processor.Build()
+			must.NoError(t, err)
+			must.Eq(t, tt.want, *built)
+
+			// Resetting a config applies the defaults with the same precedence as a build.
+			config := &Outer{In: Inner{X: 9, Y: 9}}
+			must.NoError(t, processor.ResetToDefaults(config))
+			must.Eq(t, tt.want, *config)
+		})
+	}
+}
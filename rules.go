@@ -0,0 +1,88 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// InRange returns a validator checking that the numeric field at the given path lies within [minimum, maximum]. Both
+// bounds are inclusive. Nil pointers are skipped, so optional fields are only checked when set. Use it with
+// WithValidators.
+//
+//	processor.WithValidators(konfetty.InRange[Config]("Server.Port", 1, 65535))
+func InRange[T any](path string, minimum, maximum float64) func(*T) error {
+	return ruleValidator[T](path, func(v reflect.Value) error {
+		var n float64
+
+		//nolint:exhaustive // Only numeric kinds can be compared to a range
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		default:
+			return fmt.Errorf("values of type %s can't be checked against a range", v.Type())
+		}
+
+		if n < minimum || n > maximum {
+			return fmt.Errorf("%w: %v isn't within [%v, %v]", ErrOutOfRange, v.Interface(), minimum, maximum)
+		}
+
+		return nil
+	})
+}
+
+// OneOf returns a validator checking that the field at the given path holds one of the given values. Values of a
+// different type of the same kind are converted to the field's type first, so that e.g. plain strings can be passed
+// for fields of `type Mode string`. Nil pointers are skipped, so optional fields are only checked when set. Use it
+// with WithValidators.
+//
+//	processor.WithValidators(konfetty.OneOf[Config]("Mode", "auto", "manual"))
+func OneOf[T any, V comparable](path string, values ...V) func(*T) error {
+	return ruleValidator[T](path, func(v reflect.Value) error {
+		for _, value := range values {
+			allowed := reflect.ValueOf(value)
+			if allowed.Type() != v.Type() {
+				if allowed.Kind() != v.Kind() || !allowed.CanConvert(v.Type()) {
+					return fmt.Errorf("values of type %s can't be compared to values of type %s", allowed.Type(), v.Type())
+				}
+				allowed = allowed.Convert(v.Type())
+			}
+
+			if allowed.Equal(v) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: %v isn't one of %v", ErrNotAllowed, v.Interface(), values)
+	})
+}
+
+// ruleValidator returns a validator resolving the path in the config and passing the value found to check. Errors are
+// qualified by the path.
+func ruleValidator[T any](path string, check func(reflect.Value) error) func(*T) error {
+	segments, parseErr := parsePath(path)
+
+	return func(cfg *T) error {
+		if parseErr != nil {
+			return parseErr
+		}
+
+		v, err := followPath(reflect.ValueOf(cfg).Elem(), segments)
+		if err != nil {
+			return err
+		}
+
+		if v = indirect(v); !v.IsValid() {
+			return nil
+		}
+
+		if !v.CanInterface() {
+			return fmt.Errorf("%w: %s is unexported", ErrUnknownPath, path)
+		}
+
+		return wrapPath(path, check(v))
+	}
+}
@@ -0,0 +1,154 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type ruleMode string
+
+type ruleServer struct {
+	Port    int
+	Timeout *float64
+}
+
+type ruleConfig struct {
+	Server  ruleServer
+	Mode    ruleMode
+	Retries uint8
+}
+
+func TestInRange(t *testing.T) {
+	t.Parallel()
+
+	timeout := 2.5
+
+	tests := []struct {
+		name     string
+		config   ruleConfig
+		validate func(*ruleConfig) error
+		wantErr  error
+	}{
+		{
+			name:     "Within",
+			config:   ruleConfig{Server: ruleServer{Port: 8080}},
+			validate: konfetty.InRange[ruleConfig]("Server.Port", 1, 65535),
+		},
+		{
+			name:     "InclusiveBounds",
+			config:   ruleConfig{Server: ruleServer{Port: 65535}, Retries: 1},
+			validate: konfetty.InRange[ruleConfig]("Retries", 1, 5),
+		},
+		{
+			name:     "Below",
+			config:   ruleConfig{},
+			validate: konfetty.InRange[ruleConfig]("Server.Port", 1, 65535),
+			wantErr:  konfetty.ErrOutOfRange,
+		},
+		{
+			name:     "Above",
+			config:   ruleConfig{Server: ruleServer{Timeout: &timeout}},
+			validate: konfetty.InRange[ruleConfig]("Server.Timeout", 0, 1),
+			wantErr:  konfetty.ErrOutOfRange,
+		},
+		{
+			name:     "NilPointer",
+			config:   ruleConfig{},
+			validate: konfetty.InRange[ruleConfig]("Server.Timeout", 0, 1),
+		},
+		{
+			name:     "UnknownPath",
+			config:   ruleConfig{},
+			validate: konfetty.InRange[ruleConfig]("Server.Host", 0, 1),
+			wantErr:  konfetty.ErrUnknownPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := konfetty.FromStruct(&tt.config).WithValidators(tt.validate).Build()
+			if tt.wantErr == nil {
+				must.NoError(t, err)
+				return
+			}
+			must.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("Message", func(t *testing.T) {
+		t.Parallel()
+
+		err := konfetty.InRange[ruleConfig]("Server.Port", 1, 65535)(&ruleConfig{Server: ruleServer{Port: 70000}})
+		must.EqError(t, err, "Server.Port: out of range: 70000 isn't within [1, 65535]")
+
+		err = konfetty.InRange[ruleConfig]("Mode", 1, 2)(&ruleConfig{})
+		must.ErrorContains(t, err, "can't be checked against a range")
+	})
+}
+
+func TestOneOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   ruleConfig
+		validate func(*ruleConfig) error
+		wantErr  error
+	}{
+		{
+			name:     "Allowed",
+			config:   ruleConfig{Mode: "manual"},
+			validate: konfetty.OneOf[ruleConfig]("Mode", "auto", "manual"),
+		},
+		{
+			name:     "AllowedTyped",
+			config:   ruleConfig{Mode: "auto"},
+			validate: konfetty.OneOf[ruleConfig]("Mode", ruleMode("auto")),
+		},
+		{
+			name:     "AllowedNumber",
+			config:   ruleConfig{Server: ruleServer{Port: 443}},
+			validate: konfetty.OneOf[ruleConfig]("Server.Port", 80, 443),
+		},
+		{
+			name:     "NotAllowed",
+			config:   ruleConfig{Mode: "off"},
+			validate: konfetty.OneOf[ruleConfig]("Mode", "auto", "manual"),
+			wantErr:  konfetty.ErrNotAllowed,
+		},
+		{
+			name:     "InvalidPath",
+			config:   ruleConfig{},
+			validate: konfetty.OneOf[ruleConfig]("Mode[", "auto"),
+			wantErr:  konfetty.ErrInvalidPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := konfetty.FromStruct(&tt.config).WithValidators(tt.validate).Build()
+			if tt.wantErr == nil {
+				must.NoError(t, err)
+				return
+			}
+			must.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("Message", func(t *testing.T) {
+		t.Parallel()
+
+		err := konfetty.OneOf[ruleConfig]("Mode", "auto", "manual")(&ruleConfig{Mode: "off"})
+		must.EqError(t, err, "Mode: value not allowed: off isn't one of [auto manual]")
+
+		err = konfetty.OneOf[ruleConfig]("Mode", 1)(&ruleConfig{})
+		must.ErrorContains(t, err, "can't be compared")
+	})
+}
@@ -0,0 +1,98 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// scopedDefaults are defaults that only apply within the sub-tree at path.
+type scopedDefaults struct {
+	path     string
+	segments []pathSegment
+	depth    int
+	defaults map[reflect.Type][]any
+}
+
+// WithScopedDefaults adds defaults that only apply within the sub-tree at the given path, e.g. `Rooms[2]`, which allows
+// defaulting the same type differently in different branches of the data-structure. Scoped defaults take precedence
+// over the ones added with WithDefaults, and defaults of deeper scopes take precedence over those of enclosing ones.
+// Paths that don't exist in the loaded data-structure, e.g. because of a nil pointer, are skipped. Values stored in
+// maps aren't addressable and can't be scoped into. Structurally equal defaults of a scope are applied only once, like
+// with WithDefaults.
+//
+//	processor.
+//		WithScopedDefaults("Rooms[0]", Device{Power: 10}).
+//		WithScopedDefaults("Rooms[1]", Device{Power: 20})
+func (p *Processor[T]) WithScopedDefaults(path string, defaultValues ...any) *Processor[T] {
+	segments, err := parsePath(path)
+	if err != nil {
+		p.builder.errs = append(p.builder.errs, fmt.Errorf("scoped defaults: %w", err))
+		return p
+	}
+
+	i := slices.IndexFunc(p.builder.scoped, func(s scopedDefaults) bool { return s.path == path })
+	if i < 0 {
+		i = len(p.builder.scoped)
+		p.builder.scoped = append(p.builder.scoped, scopedDefaults{
+			path:     path,
+			segments: segments,
+			depth:    len(segments),
+			defaults: make(map[reflect.Type][]any),
+		})
+	}
+
+	scope := p.builder.scoped[i]
+	for _, dv := range defaultValues {
+		t := reflect.TypeOf(dv)
+		if err = checkDefaultType(t); err != nil {
+			p.builder.errs = append(p.builder.errs, err)
+			continue
+		}
+
+		scope.defaults[t] = appendDefault(scope.defaults[t], dv)
+	}
+
+	return p
+}
+
+// applyScopedDefaults applies the scoped defaults to their sub-trees of cfg, deepest scopes first, so that their
+// values take precedence.
+func (b *Builder[T]) applyScopedDefaults(cfg *T, report *Report) error {
+	scopes := append([]scopedDefaults(nil), b.scoped...)
+	sort.SliceStable(scopes, func(i, j int) bool { return scopes[i].depth > scopes[j].depth })
+
+	for _, scope := range scopes {
+		v, err := lookupPath(reflect.ValueOf(cfg).Elem(), scope.path)
+		if errors.Is(err, ErrUnknownPath) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if !v.CanSet() {
+			return fmt.Errorf("%s: scoped defaults can't be applied to values that aren't addressable", scope.path)
+		}
+
+		d := b.defaulter()
+		d.defaults = scope.defaults
+		d.scope = scope.path
+		d.computed = nil
+		d.catchAll = nil
+		d.interfaceDefaults = nil
+		d.tagDefaults = false
+		d.report = report
+		d.visited = make(map[uintptr]bool)
+
+		// The changes are reported with the same field names as the ones of the other defaults, see WithPathTag.
+		path := b.tags().tagPath(reflect.TypeFor[T](), scope.segments)
+		if err = d.applyDefaultsRecursive(v, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
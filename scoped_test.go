@@ -0,0 +1,125 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithScopedDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name  string
+		Power int
+	}
+
+	type Room struct {
+		Name    string
+		Devices []Device
+		Main    *Device
+	}
+
+	type Config struct {
+		Rooms  []Room
+		Garage *Room
+		Named  map[string]Room
+	}
+
+	newConfig := func() *Config {
+		return &Config{Rooms: []Room{
+			{Devices: []Device{{}, {Power: 1}}},
+			{Devices: []Device{{}}, Main: &Device{}},
+			{Devices: []Device{{}}},
+		}}
+	}
+
+	t.Run("Branches", func(t *testing.T) {
+		t.Parallel()
+
+		result, report, err := konfetty.FromStruct(newConfig()).
+			WithDefaults(Device{Name: "device", Power: 5}, Room{Name: "room"}).
+			WithScopedDefaults("Rooms[0]", Device{Power: 10}).
+			WithScopedDefaults("Rooms[1]", Device{Name: "kitchen device", Power: 20}, Room{Name: "kitchen"}).
+			WithScopedDefaults("Rooms[1].Main", Device{Name: "main"}).
+			WithScopedDefaults("Garage", Device{Power: 30}).
+			BuildWithReport()
+		must.NoError(t, err)
+
+		must.Eq(t, []Room{
+			{Name: "room", Devices: []Device{{Name: "device", Power: 10}, {Name: "device", Power: 1}}},
+			{
+				Name:    "kitchen",
+				Devices: []Device{{Name: "kitchen device", Power: 20}},
+				Main:    &Device{Name: "main", Power: 20},
+			},
+			{Name: "room", Devices: []Device{{Name: "device", Power: 5}}},
+		}, result.Rooms)
+		must.Nil(t, result.Garage)
+
+		must.Eq(t, "Rooms[1].Main.Name", report.Changes[0].Path)
+	})
+
+	t.Run("PathTag", func(t *testing.T) {
+		t.Parallel()
+
+		type Light struct {
+			Power int `json:"power"`
+		}
+
+		type House struct {
+			Lights []Light `json:"lights"`
+			Any    any
+		}
+
+		_, report, err := konfetty.FromStruct(&House{Lights: []Light{{}}, Any: &Light{}}).
+			WithScopedDefaults("Lights[0]", Light{Power: 10}).
+			WithScopedDefaults("Any", Light{Power: 20}).
+			WithPathTag("json").
+			BuildWithReport()
+		must.NoError(t, err)
+
+		paths := make([]string, 0, len(report.Changes))
+		for _, change := range report.Changes {
+			paths = append(paths, change.Path)
+		}
+		must.SliceContainsAll(t, []string{"lights[0].power", "Any.power"}, paths)
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		// Structurally equal defaults of a scope are registered once, like the ones added with WithDefaults.
+		_, report, err := konfetty.FromStruct(newConfig()).
+			WithScopedDefaults("Rooms[0]", Device{Power: 10}).
+			WithScopedDefaults("Rooms[0]", Device{Power: 10}, Device{Name: "device"}).
+			WithoutGlobalDefaults().
+			BuildWithReport()
+		must.NoError(t, err)
+		must.Eq(t, 2, report.Stats.DefaultsRegistered)
+	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(newConfig()).WithScopedDefaults("Rooms[0", Device{}).Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidPath)
+	})
+
+	t.Run("InvalidDefault", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(newConfig()).WithScopedDefaults("Rooms[0]", 42).Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidDefault)
+	})
+
+	t.Run("NotAddressable", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{Named: map[string]Room{"office": {}}}
+		_, err := konfetty.FromStruct(config).WithScopedDefaults("Named[office]", Device{}).Build()
+		must.ErrorContains(t, err, "Named[office]: scoped defaults can't be applied")
+	})
+}
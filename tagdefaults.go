@@ -0,0 +1,62 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/nikoksr/konfetty/internal/convert"
+)
+
+// defaultTagKey is the struct tag key field defaults are read from, see WithDefaultsFromStructTags.
+const defaultTagKey = "default"
+
+// WithDefaultsFromStructTags enables reading defaults from `default` struct tags, e.g. `default:"8080"`. The tag value
+// is parsed according to the field's type, just like the values of environment variables, so it supports strings,
+// booleans, numbers, durations, types implementing encoding.TextUnmarshaler and pointers to those.
+//
+// Tag defaults have the lowest precedence. They only fill fields that are still unset after all other defaults were
+// applied, including the ones registered via WithDefaults, scoped, field and computed defaults. Tags are ignored
+// unless this option is used, so that `default` tags meant for other libraries don't change existing behavior.
+//
+//	type ServerConfig struct {
+//		Host    string        `default:"localhost"`
+//		Timeout time.Duration `default:"30s"`
+//	}
+func (p *Processor[T]) WithDefaultsFromStructTags() *Processor[T] {
+	p.builder.tagDefaults = true
+	return p
+}
+
+// applyTagDefault sets v, the value of the given struct field, to the value of the field's default tag, if it has one
+// and v is unset.
+func (d *defaulter) applyTagDefault(v reflect.Value, field reflect.StructField, path string) error {
+	tag, ok := field.Tag.Lookup(defaultTagKey)
+	if !ok || tag == "" || !v.CanSet() || !d.isZero(v) {
+		return nil
+	}
+
+	value := reflect.New(field.Type).Elem()
+	target := value
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	if !convert.CanSetString(target.Type()) {
+		return fmt.Errorf("%s: %w: %w: default tag on field of unsupported type %s",
+			path, ErrInvalidTag, ErrUnsupportedDefaultKind, field.Type)
+	}
+
+	if err := convert.SetString(target, tag); err != nil {
+		return fmt.Errorf("%s: %w: %w: default tag %q: %w", path, ErrInvalidTag, ErrDefaultParse, tag, err)
+	}
+
+	before := reflect.New(v.Type()).Elem()
+	before.Set(v)
+	v.Set(value)
+
+	d.origin = registeredDefault{index: -1, tag: tag}
+	d.record(path, before, v)
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package konfetty_test
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestWithDefaultsFromStructTags(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Host    string        `default:"localhost"`
+		Port    int           `default:"8080"`
+		Timeout time.Duration `default:"30s"`
+		Debug   *bool         `default:"true"`
+		Started time.Time     `default:"2024-01-02T03:04:05Z"`
+		Name    string        `default:""`
+	}
+
+	type Config struct {
+		Server  Server
+		Servers []Server
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{}).Build()
+		must.NoError(t, err)
+		must.Eq(t, Server{}, result.Server)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{Servers: []Server{{Port: 9090}}}).
+			WithDefaultsFromStructTags().
+			Build()
+		must.NoError(t, err)
+
+		must.Eq(t, "localhost", result.Server.Host)
+		must.Eq(t, 8080, result.Server.Port)
+		must.Eq(t, 30*time.Second, result.Server.Timeout)
+		must.NotNil(t, result.Server.Debug)
+		must.True(t, *result.Server.Debug)
+		must.Eq(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), result.Server.Started)
+		must.Eq(t, "", result.Server.Name)
+
+		must.Eq(t, "localhost", result.Servers[0].Host)
+		must.Eq(t, 9090, result.Servers[0].Port)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		t.Parallel()
+
+		// Registered defaults win over tags, which only fill the fields left unset.
+		result, err := konfetty.FromStruct(&Config{Server: Server{Host: "example.com"}}).
+			WithDefaults(Server{Host: "default.com", Port: 443}).
+			WithDefaultsFromStructTags().
+			Build()
+		must.NoError(t, err)
+		must.Eq(t, "example.com", result.Server.Host)
+		must.Eq(t, 443, result.Server.Port)
+		must.Eq(t, 30*time.Second, result.Server.Timeout)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		t.Parallel()
+
+		_, report, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(Server{Port: 443}).
+			WithDefaultsFromStructTags().
+			BuildWithReport()
+		must.NoError(t, err)
+
+		var tagged []string
+		for _, c := range report.Changes {
+			if c.Tag != "" {
+				tagged = append(tagged, c.String())
+			}
+		}
+		must.SliceContains(t, tagged, `Server.Host:  -> localhost (default tag "localhost")`)
+		must.SliceNotContains(t, tagged, `Server.Port: 0 -> 8080 (default tag "8080")`)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		type InvalidValue struct {
+			Port int `default:"http"`
+		}
+
+		_, err := konfetty.FromStruct(&InvalidValue{}).WithDefaultsFromStructTags().Build()
+		must.Error(t, err)
+		must.True(t, errors.Is(err, konfetty.ErrInvalidTag))
+		must.StrContains(t, err.Error(), "Port")
+
+		type UnsupportedType struct {
+			Hosts []string `default:"a,b"`
+		}
+
+		_, err = konfetty.FromStruct(&UnsupportedType{}).WithDefaultsFromStructTags().Build()
+		must.True(t, errors.Is(err, konfetty.ErrInvalidTag))
+
+		// Without the option, tags aren't even parsed.
+		_, err = konfetty.FromStruct(&UnsupportedType{}).Build()
+		must.NoError(t, err)
+	})
+}
+
+func TestStructTagDefaultsNetworkTypes(t *testing.T) {
+	t.Parallel()
+
+	type Upstream struct {
+		URL   url.URL  `default:"https://example.com:8443/api?v=2"`
+		Proxy *url.URL `default:"http://proxy.internal:3128"`
+		IP    net.IP   `default:"10.0.0.1"`
+		Mask  *net.IP  `default:"255.255.255.0"`
+	}
+
+	type Config struct {
+		Upstreams []Upstream
+	}
+
+	set := url.URL{Scheme: "http", Host: "localhost"}
+	result, err := konfetty.FromStruct(&Config{Upstreams: []Upstream{{}, {URL: set, IP: net.ParseIP("::1")}}}).
+		WithDefaultsFromStructTags().
+		Build()
+	must.NoError(t, err)
+
+	defaulted := result.Upstreams[0]
+	must.Eq(t, url.URL{Scheme: "https", Host: "example.com:8443", Path: "/api", RawQuery: "v=2"}, defaulted.URL)
+	must.Eq(t, &url.URL{Scheme: "http", Host: "proxy.internal:3128"}, defaulted.Proxy)
+	must.Eq(t, net.ParseIP("10.0.0.1"), defaulted.IP)
+	must.NotNil(t, defaulted.Mask)
+	must.Eq(t, net.ParseIP("255.255.255.0"), *defaulted.Mask)
+
+	must.Eq(t, set, result.Upstreams[1].URL)
+	must.Eq(t, net.ParseIP("::1"), result.Upstreams[1].IP)
+
+	type Invalid struct {
+		URL url.URL `default:"http://[::1"`
+	}
+
+	_, err = konfetty.FromStruct(&Invalid{}).WithDefaultsFromStructTags().Build()
+	must.ErrorIs(t, err, konfetty.ErrDefaultParse)
+}
@@ -0,0 +1,134 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagKey is the struct tag key konfetty reads its field options from by default.
+const tagKey = "konfetty"
+
+// Merge strategies that can be selected per field via the `merge` tag option.
+const (
+	mergeFill = "fill"
+	mergeAdd  = "add"
+)
+
+// tagOptions holds the parsed konfetty options of a single struct field.
+type tagOptions struct {
+	merge string
+
+	// weakRef marks a field as a cycle-breaking edge, e.g. a back-reference to a parent. Weak references are never
+	// descended into.
+	weakRef bool
+
+	// pattern is a regular expression string fields have to match during validation.
+	pattern string
+
+	// skip excludes a field and everything below it from processing, see tagResolver.skips.
+	skip bool
+
+	// noDefault keeps the defaults of the enclosing struct, field defaulters and default tags from filling the field,
+	// while the values below it are still traversed and defaulted, unlike with skip. Scalar fields have nothing below
+	// them, so they don't receive the defaults of their own types either, e.g. the ones of a named type like Port.
+	noDefault bool
+
+	// normalize lists the normalizations applied to string fields in order, e.g. `trim` and `lower`, see
+	// normalizeStructure.
+	normalize []string
+}
+
+// tagResolver is the central place for reading konfetty options from struct tags. It checks the configured tag keys
+// in order of priority and uses the first one present on a field. The zero value only checks the konfetty key.
+type tagResolver struct {
+	keys []string
+
+	// cache optionally caches the field metadata of struct types, see fields.
+	cache *typeCache
+
+	// pathTag is the struct tag key field names in paths are read from, e.g. `json`. If empty or absent on a field,
+	// the Go field name is used.
+	pathTag string
+}
+
+// fieldName returns the name of the field used in paths, see pathTag.
+func (r tagResolver) fieldName(field reflect.StructField) string {
+	if r.pathTag == "" {
+		return field.Name
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get(r.pathTag), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+// lookup returns the value of the highest priority tag key present on the field.
+func (r tagResolver) lookup(field reflect.StructField) (string, bool) {
+	if len(r.keys) == 0 {
+		return field.Tag.Lookup(tagKey)
+	}
+
+	for _, key := range r.keys {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			return tag, true
+		}
+	}
+
+	return "", false
+}
+
+// skips reports whether the field is tagged with `konfetty:"-"`, which excludes the field and everything below it from
+// defaulting, interpolation and validation.
+func (r tagResolver) skips(field reflect.StructField) bool {
+	tag, ok := r.lookup(field)
+	return ok && tag == "-"
+}
+
+// parse parses the konfetty options of a struct field. Options are separated by commas and may carry a value, e.g.
+// `konfetty:"merge=add"`. Unknown options are ignored. Since regular expressions may contain commas, the pattern
+// option consumes the rest of the tag and has to come last, e.g. `konfetty:"weakref,pattern=^[a-z]{1,8}$"`.
+func (r tagResolver) parse(field reflect.StructField) (tagOptions, error) {
+	opts := tagOptions{merge: mergeFill}
+
+	tag, ok := r.lookup(field)
+	if !ok || tag == "" {
+		return opts, nil
+	}
+
+	if tag == "-" {
+		opts.skip = true
+		return opts, nil
+	}
+
+	for tag != "" {
+		var option string
+		option, tag, _ = strings.Cut(tag, ",")
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch name {
+		case "merge":
+			if value != mergeFill && value != mergeAdd {
+				return opts, fmt.Errorf("%w: unknown merge strategy %q on field %s", ErrInvalidTag, value, field.Name)
+			}
+			opts.merge = value
+		case "weakref":
+			opts.weakRef = true
+		case "nodefault":
+			opts.noDefault = true
+		case normalizeTrim, normalizeLower, normalizeUpper:
+			opts.normalize = append(opts.normalize, name)
+		case "pattern":
+			if tag != "" {
+				value += "," + tag
+				tag = ""
+			}
+			opts.pattern = value
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,26 @@
+// Command mainscalar checks that named scalar types declared in package main, whose package path has no domain, can
+// act as defaults. It's run by TestNamedScalarInMainPackage.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type Port int
+
+type Config struct {
+	Port Port
+}
+
+func main() {
+	cfg, err := konfetty.FromStruct(&Config{}).WithDefaults(Port(8080)).Build()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Print(cfg.Port)
+}
@@ -0,0 +1,65 @@
+package konfetty
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithTimeout limits the duration of every build, covering all of its steps from loading the data-structure to the
+// validators. The context passed to context-aware providers expires after the timeout. Steps that don't observe a
+// context, e.g. a hanging validator, can't be interrupted; Build stops waiting for them and returns an error wrapping
+// context.DeadlineExceeded, while they finish in the background. The build checks the context between its steps, so
+// no further step is started after the timeout, but until the running one returns, it may still modify the struct
+// passed to FromStruct, which stays locked against other builds in the meantime. Panics in it crash the program
+// unless WithRecover is used. A timeout of zero or less disables the limit.
+//
+//	cfg, err := processor.WithTimeout(5 * time.Second).Build()
+//	if errors.Is(err, context.DeadlineExceeded) {
+//		// ...
+//	}
+func (p *Processor[T]) WithTimeout(timeout time.Duration) *Processor[T] {
+	p.builder.timeout = timeout
+	return p
+}
+
+// withTimeout calls fn with a context expiring after the timeout and returns its results, or an error wrapping the
+// context's error if the context is done before fn returns. Without a timeout, fn is called directly.
+func withTimeout[R any](ctx context.Context, timeout time.Duration, fn func(context.Context) (R, error)) (R, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		value R
+		err   error
+	}
+
+	// The channel is buffered, so that fn can finish in the background after the deadline passed.
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(ctx)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero R
+		return zero, fmt.Errorf("build timed out after %s: %w", timeout, ctx.Err())
+	}
+}
+
+// checkContext returns an error wrapping the context's error if the context is done, so that a build doesn't start the
+// given step after its timeout passed or its context was canceled.
+func checkContext(ctx context.Context, step string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", step, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package konfetty_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+// SlowProvider takes the given delay to load, without observing a context.
+type SlowProvider struct {
+	delay time.Duration
+}
+
+func (s SlowProvider) Load() (TestConfig, error) {
+	time.Sleep(s.delay)
+	return TestConfig{Name: "Slow"}, nil
+}
+
+// BlockingContextProvider blocks until its context is done.
+type BlockingContextProvider struct {
+	MockProvider
+}
+
+func (b *BlockingContextProvider) LoadContext(ctx context.Context) (TestConfig, error) {
+	<-ctx.Done()
+	return TestConfig{}, ctx.Err()
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SlowProvider", func(t *testing.T) {
+		t.Parallel()
+
+		start := time.Now()
+		_, err := konfetty.FromProvider(SlowProvider{delay: time.Second}).
+			WithTimeout(20 * time.Millisecond).
+			Build()
+		must.ErrorIs(t, err, context.DeadlineExceeded)
+		must.Less(t, 500*time.Millisecond, time.Since(start))
+	})
+
+	t.Run("ContextProvider", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromProvider[TestConfig](&BlockingContextProvider{}).
+			WithTimeout(20 * time.Millisecond).
+			Build()
+		must.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("SlowValidator", func(t *testing.T) {
+		t.Parallel()
+
+		_, report, err := konfetty.FromStruct(&TestConfig{}).
+			WithValidator(func(*TestConfig) error {
+				time.Sleep(time.Second)
+				return nil
+			}).
+			WithTimeout(20 * time.Millisecond).
+			BuildWithReport()
+		must.ErrorIs(t, err, context.DeadlineExceeded)
+		must.Nil(t, report)
+	})
+
+	t.Run("StopsAfterTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		var transforms atomic.Int32
+
+		processor := konfetty.FromStruct(&TestConfig{}).
+			WithOnLoaded(func(*TestConfig) { <-release }).
+			WithTransformer(func(*TestConfig) { transforms.Add(1) }).
+			WithTimeout(50 * time.Millisecond)
+
+		_, err := processor.Build()
+		must.ErrorIs(t, err, context.DeadlineExceeded)
+		close(release)
+
+		// The second build waits for the first one to unlock the struct, which doesn't run its transformer anymore.
+		_, err = processor.Build()
+		must.NoError(t, err)
+		must.Eq(t, 1, transforms.Load())
+	})
+
+	t.Run("InTime", func(t *testing.T) {
+		t.Parallel()
+
+		before, after, err := konfetty.FromProvider(SlowProvider{delay: time.Millisecond}).
+			WithDefaults(TestConfig{Age: 30}).
+			WithTimeout(time.Second).
+			BuildWithBeforeAfter()
+		must.NoError(t, err)
+		must.Eq(t, 0, before.Age)
+		must.Eq(t, 30, after.Age)
+	})
+}
@@ -0,0 +1,161 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errSkipChildren can be returned by a visitFunc to skip the children of the visited value.
+var errSkipChildren = errors.New("skip children")
+
+// visitFunc is called by traverse for every value it reaches, along with the value's path relative to the root.
+type visitFunc func(v reflect.Value, path string) error
+
+// traverse calls visit for v and, recursively, for every value reachable from it: exported struct fields, slice and
+// array elements, map values and the targets of pointers and interfaces. Values are visited before their children.
+// Struct fields tagged with `konfetty:"-"` according to tags are skipped along with everything below them. The bytes
+// of byte slices, e.g. json.RawMessage, aren't visited individually. Map values are visited in the order of their
+// sorted keys, so that the order of the visits is deterministic.
+//
+// Map values and values stored in interfaces aren't addressable. They are visited as addressable copies which are
+// written back afterwards, so visit can modify every value it receives as long as the root is addressable. Pointers
+// that are already on the current traversal path are skipped to break cycles.
+func traverse(v reflect.Value, tags tagResolver, visit visitFunc) error {
+	t := &traversal{tags: tags, visit: visit, visited: make(map[uintptr]bool)}
+
+	return t.value(v, "")
+}
+
+// traversal holds the state of a single traverse call.
+type traversal struct {
+	tags  tagResolver
+	visit visitFunc
+
+	// visited holds the pointers on the current traversal path and is used to break cycles.
+	visited map[uintptr]bool
+}
+
+func (t *traversal) value(v reflect.Value, path string) error {
+	if err := t.visit(v, path); err != nil {
+		if errors.Is(err, errSkipChildren) {
+			return nil
+		}
+
+		return err
+	}
+
+	//nolint:exhaustive // Only container kinds have children
+	switch v.Kind() {
+	case reflect.Struct:
+		for i, field := range t.tags.fields(v.Type()) {
+			if !field.IsExported() || field.opts.skip {
+				continue
+			}
+
+			if err := t.value(v.Field(i), joinPath(path, t.tags.fieldName(field.StructField))); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if isBytes(v.Type()) {
+			return nil
+		}
+
+		for i := range v.Len() {
+			if err := t.value(v.Index(i), indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return t.mapValues(v, path)
+	case reflect.Ptr:
+		if v.IsNil() || t.visited[v.Pointer()] {
+			return nil
+		}
+
+		t.visited[v.Pointer()] = true
+		defer delete(t.visited, v.Pointer())
+
+		return t.value(v.Elem(), path)
+	case reflect.Interface:
+		return t.interfaceValue(v, path)
+	default:
+		// Other kinds don't have children
+	}
+
+	return nil
+}
+
+func (t *traversal) mapValues(v reflect.Value, path string) error {
+	for _, key := range sortedKeys(v) {
+		value := v.MapIndex(key)
+		if !value.IsValid() {
+			// Keys that aren't equal to themselves, e.g. NaN, can't be looked up, so their values can't be visited.
+			continue
+		}
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(value)
+
+		if err := t.value(elem, keyPath(path, key)); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, elem)
+	}
+
+	return nil
+}
+
+func (t *traversal) interfaceValue(v reflect.Value, path string) error {
+	if v.IsNil() {
+		return nil
+	}
+
+	elem := v.Elem()
+	if elem.Kind() == reflect.Ptr {
+		return t.value(elem, path)
+	}
+
+	elemCopy := reflect.New(elem.Type()).Elem()
+	elemCopy.Set(elem)
+
+	if err := t.value(elemCopy, path); err != nil {
+		return err
+	}
+
+	if v.CanSet() {
+		v.Set(elemCopy)
+	}
+
+	return nil
+}
+
+//nolint:gochecknoglobals // Immutable type descriptor
+var byteType = reflect.TypeFor[byte]()
+
+// isBytes reports whether t is a byte slice, e.g. []byte or json.RawMessage, whose elements are never traversed. Slices
+// of named byte types aren't treated as bytes, as their elements may have defaults of their own.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem() == byteType
+}
+
+// joinPath appends a struct field name to a path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// indexPath appends a slice index to a path.
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// keyPath appends a map key to a path.
+func keyPath(path string, key reflect.Value) string {
+	return fmt.Sprintf("%s[%v]", path, key.Interface())
+}
@@ -0,0 +1,71 @@
+package konfetty
+
+import (
+	"reflect"
+	"sync"
+)
+
+// fieldInfo holds the reflection metadata of a struct field that is needed while processing it.
+type fieldInfo struct {
+	reflect.StructField
+
+	// opts holds the parsed konfetty options of the field and err the error parsing them, if any.
+	opts tagOptions
+	err  error
+}
+
+// typeCache maps struct types to the metadata of their fields, so that repeated builds don't resolve the fields and
+// parse their tags over and over again. It is safe for concurrent use.
+type typeCache struct {
+	fields sync.Map // map[reflect.Type][]fieldInfo
+	flat   sync.Map // map[reflect.Type]bool
+}
+
+// fields returns the metadata of the fields of the struct type t, in declaration order. The result is cached if the
+// resolver has a cache and must not be modified.
+func (r tagResolver) fields(t reflect.Type) []fieldInfo {
+	if r.cache != nil {
+		if fields, ok := r.cache.fields.Load(t); ok {
+			//nolint:forcetypeassert // The cache only holds field metadata
+			return fields.([]fieldInfo)
+		}
+	}
+
+	fields := make([]fieldInfo, t.NumField())
+	for i := range fields {
+		fields[i].StructField = t.Field(i)
+		fields[i].opts, fields[i].err = r.parse(fields[i].StructField)
+	}
+
+	if r.cache != nil {
+		r.cache.fields.Store(t, fields)
+	}
+
+	return fields
+}
+
+// flat reports whether the struct type t, whose fields are given, only has exported, non-embedded fields of scalar
+// types with valid tags, so that defaulting it doesn't need to recurse into its fields. The result is cached if the
+// resolver has a cache.
+func (r tagResolver) flat(t reflect.Type, fields []fieldInfo) bool {
+	if r.cache != nil {
+		if flat, ok := r.cache.flat.Load(t); ok {
+			//nolint:forcetypeassert // The cache only holds flags
+			return flat.(bool)
+		}
+	}
+
+	flat := true
+	for _, field := range fields {
+		if !field.IsExported() || field.Anonymous || field.err != nil || !isScalar(field.Type.Kind()) {
+			flat = false
+			break
+		}
+	}
+
+	if r.cache != nil {
+		r.cache.flat.Store(t, flat)
+	}
+
+	return flat
+}
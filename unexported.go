@@ -0,0 +1,76 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// findUnexportedDefaults walks the type t and returns an ErrUnexportedDefault error for every unexported field whose
+// type has registered defaults. Such defaults are silently skipped while merging, since unexported fields can't be set
+// through reflection.
+func findUnexportedDefaults(t reflect.Type, defaults map[reflect.Type][]any, tags tagResolver) error {
+	fields := unexportedDefaultFields(t, defaults, tags)
+
+	errs := make([]error, 0, len(fields))
+	for _, field := range fields {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrUnexportedDefault, field))
+	}
+
+	return errors.Join(errs...)
+}
+
+// unexportedDefault is an unexported field whose type has registered defaults.
+type unexportedDefault struct {
+	path string
+	typ  reflect.Type
+}
+
+func (u unexportedDefault) String() string {
+	return fmt.Sprintf("%s of type %s is unexported", u.path, u.typ)
+}
+
+// unexportedDefaultFields walks the type t and returns every unexported field whose type has registered defaults.
+// Embedded structs that aren't pointers are walked like exported fields, since defaults are applied through them.
+func unexportedDefaultFields(t reflect.Type, defaults map[reflect.Type][]any, tags tagResolver) []unexportedDefault {
+	var fields []unexportedDefault
+	visited := make(map[reflect.Type]bool)
+
+	var walk func(t reflect.Type, path string)
+	walk = func(t reflect.Type, path string) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			if t.Kind() != reflect.Ptr {
+				path += "[]"
+			}
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct || visited[t] {
+			return
+		}
+		visited[t] = true
+
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if tags.skips(field) {
+				continue
+			}
+
+			fieldPath := joinPath(path, tags.fieldName(field))
+
+			// The fields of unexported embedded structs are promoted and can be set, so they receive defaults.
+			if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+				if ft := derefType(field.Type); len(defaults[ft]) > 0 || len(defaults[reflect.PointerTo(ft)]) > 0 {
+					fields = append(fields, unexportedDefault{path: fieldPath, typ: ft})
+				}
+
+				continue
+			}
+
+			walk(field.Type, fieldPath)
+		}
+	}
+	walk(t, "")
+
+	return fields
+}
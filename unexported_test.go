@@ -0,0 +1,80 @@
+package konfetty_test
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+type privateSettings struct {
+	Mode string
+}
+
+func TestStrictUnexportedDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Room struct {
+		Name     string
+		settings *privateSettings //nolint:unused // Used for testing unexported fields
+	}
+
+	type Config struct {
+		privateSettings //nolint:unused // Used for testing unexported embedded structs
+		Rooms           []Room
+		Ignored         Room `konfetty:"-"`
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(privateSettings{Mode: "auto"}).
+			WithStrict().
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrUnexportedDefault)
+		must.ErrorContains(t, err, "Rooms[].settings of type konfetty_test.privateSettings is unexported")
+		must.StrNotContains(t, err.Error(), "privateSettings of type")
+		must.StrNotContains(t, err.Error(), "Ignored")
+	})
+
+	t.Run("NotStrict", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(privateSettings{Mode: "auto"}).
+			Build()
+		must.NoError(t, err)
+
+		// The fields promoted from unexported embedded structs can be set, so they receive defaults.
+		must.Eq(t, "auto", result.Mode)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		t.Parallel()
+
+		_, report, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(privateSettings{Mode: "auto"}).
+			BuildWithReport()
+		must.NoError(t, err)
+
+		paths := make([]string, 0, len(report.Warnings))
+		for _, warning := range report.Warnings {
+			if warning.Category == konfetty.WarningUnexportedDefault {
+				paths = append(paths, warning.Path)
+			}
+		}
+		must.Eq(t, []string{"Rooms[].settings"}, paths)
+	})
+
+	t.Run("NoDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(Room{Name: "room"}).
+			WithStrict().
+			Build()
+		must.NoError(t, err)
+	})
+}
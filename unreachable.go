@@ -0,0 +1,83 @@
+package konfetty
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// findUnreachableDefaults returns an ErrUnreachableDefault error for every type with registered defaults that doesn't
+// occur in the type graph of t, so that its defaults can never be applied. Types that may be stored in reachable
+// interfaces count as occurring.
+func findUnreachableDefaults(t reflect.Type, defaults map[reflect.Type][]any, tags tagResolver) error {
+	reachable, interfaces := reachableTypes(t, tags)
+
+	var errs []error
+	for dt := range defaults {
+		target := dt
+		if target.Kind() == reflect.Ptr {
+			// Defaults registered as pointers apply to the values they point to.
+			target = target.Elem()
+		}
+
+		if reachable[target] || implementsAny(target, interfaces) {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%w: %s doesn't occur in %s", ErrUnreachableDefault, dt, t))
+	}
+
+	// Map iteration order is random, so the errors are sorted to keep them stable.
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return errors.Join(errs...)
+}
+
+// reachableTypes walks the type t and returns every type occurring in it, along with the interface types values may be
+// stored in. Fields excluded from processing by their tag are skipped.
+func reachableTypes(t reflect.Type, tags tagResolver) (map[reflect.Type]bool, []reflect.Type) {
+	reachable := make(map[reflect.Type]bool)
+	var interfaces []reflect.Type
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		if reachable[t] {
+			return
+		}
+		reachable[t] = true
+
+		//nolint:exhaustive // Only composite kinds contain other types
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			walk(t.Elem())
+		case reflect.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case reflect.Interface:
+			interfaces = append(interfaces, t)
+		case reflect.Struct:
+			for i := range t.NumField() {
+				if field := t.Field(i); !tags.skips(field) {
+					walk(field.Type)
+				}
+			}
+		default:
+			// Other kinds don't contain other types
+		}
+	}
+	walk(t)
+
+	return reachable, interfaces
+}
+
+// implementsAny reports whether values of type t, or pointers to them, can be stored in any of the interfaces.
+func implementsAny(t reflect.Type, interfaces []reflect.Type) bool {
+	for _, iface := range interfaces {
+		if t.Implements(iface) || reflect.PointerTo(t).Implements(iface) {
+			return true
+		}
+	}
+
+	return false
+}
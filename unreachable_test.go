@@ -0,0 +1,80 @@
+package konfetty_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestStrictUnreachableDefaults(t *testing.T) {
+	t.Parallel()
+
+	type ServerConfig struct {
+		Port int
+	}
+
+	type DatabaseConfig struct {
+		Host string
+	}
+
+	type PluginConfig struct {
+		Name string
+	}
+
+	type Level string
+
+	type Config struct {
+		Database *DatabaseConfig
+		Replicas map[string][]DatabaseConfig
+		Level    Level
+		Plugin   fmt.Stringer
+		Ignored  ServerConfig `konfetty:"-"`
+	}
+
+	t.Run("Unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(ServerConfig{Port: 8080}, &PluginConfig{Name: "plugin"}).
+			WithStrict().
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrUnreachableDefault)
+		must.ErrorContains(t, err, "konfetty_test.ServerConfig doesn't occur in konfetty_test.Config")
+		must.ErrorContains(t, err, "*konfetty_test.PluginConfig doesn't occur")
+	})
+
+	t.Run("Reachable", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(
+				DatabaseConfig{Host: "localhost"},
+				&DatabaseConfig{Host: "localhost"},
+				map[string][]DatabaseConfig{},
+				Level("info"),
+				// Could be stored in the Plugin interface.
+				stringerPlugin{},
+			).
+			WithStrict().
+			Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("NotStrict", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&Config{}).
+			WithDefaults(ServerConfig{Port: 8080}).
+			Build()
+		must.NoError(t, err)
+	})
+}
+
+type stringerPlugin struct {
+	Name string
+}
+
+func (p stringerPlugin) String() string { return p.Name }
@@ -0,0 +1,118 @@
+package konfetty
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// patternCache holds the compiled regular expressions of pattern tags, keyed by their source, so that every pattern is
+// only compiled once per process.
+//
+//nolint:gochecknoglobals // Compiled patterns are immutable and safe to share between builds
+var patternCache sync.Map
+
+// Validatable is implemented by types that can validate themselves. During the build, Validate is called on every
+// value reachable from the data-structure that implements it, including the elements of slices of interfaces, e.g.
+// the different device types stored in a `[]any`. Validation errors are prefixed with the path of the invalid value.
+type Validatable interface {
+	Validate() error
+}
+
+//nolint:gochecknoglobals // Immutable type descriptor
+var validatableType = reflect.TypeFor[Validatable]()
+
+// validateStructure runs the built-in validations on the config. It enforces the validation options of the konfetty
+// tags, e.g. `konfetty:"pattern=^[a-z]+$"`, and calls Validate on every value implementing Validatable. It returns an
+// error for the first invalid value, prefixed with the value's path.
+func validateStructure(config any, tags tagResolver) error {
+	return traverse(reflect.ValueOf(config), tags, func(v reflect.Value, path string) error {
+		if err := validateValue(v); err != nil {
+			return wrapPath(path, err)
+		}
+
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+
+		for i, field := range tags.fields(v.Type()) {
+			if !field.IsExported() {
+				continue
+			}
+
+			if field.err != nil {
+				return field.err
+			}
+
+			if err := validateField(v.Field(i), field.StructField, field.opts); err != nil {
+				return fmt.Errorf("%s: %w", joinPath(path, tags.fieldName(field.StructField)), err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// validateValue calls Validate if v implements Validatable. Pointers and interfaces are skipped, as their targets are
+// visited on their own, which makes sure every value is validated once, no matter the receiver type of Validate.
+func validateValue(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || !v.CanInterface() {
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(validatableType) {
+		//nolint:errcheck,forcetypeassert // The type implements Validatable
+		return v.Addr().Interface().(Validatable).Validate()
+	}
+
+	if v.Type().Implements(validatableType) {
+		//nolint:errcheck,forcetypeassert // The type implements Validatable
+		return v.Interface().(Validatable).Validate()
+	}
+
+	return nil
+}
+
+func validateField(v reflect.Value, field reflect.StructField, opts tagOptions) error {
+	if opts.pattern == "" {
+		return nil
+	}
+
+	re, err := compilePattern(opts.pattern)
+	if err != nil {
+		return fmt.Errorf("%w: invalid pattern on field %s: %w", ErrInvalidTag, field.Name, err)
+	}
+
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("%w: pattern requires a string field, but %s is of kind %s", ErrInvalidTag, field.Name, v.Kind())
+	}
+
+	if !re.MatchString(v.String()) {
+		return fmt.Errorf("%w: %q doesn't match %q", ErrPatternMismatch, v.String(), opts.pattern)
+	}
+
+	return nil
+}
+
+// compilePattern returns the compiled regular expression for the pattern, compiling it on first use.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		//nolint:forcetypeassert // The cache only holds compiled patterns
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patternCache.Store(pattern, re)
+
+	return re, nil
+}
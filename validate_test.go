@@ -0,0 +1,430 @@
+package konfetty_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+)
+
+func TestPatternTag(t *testing.T) {
+	t.Parallel()
+
+	type Room struct {
+		Slug  string  `konfetty:"pattern=^[a-z0-9-]+$"`
+		Short *string `konfetty:"pattern=^[a-z]{1,3}$"`
+	}
+
+	type Config struct {
+		Rooms []Room
+	}
+
+	t.Run("Matching", func(t *testing.T) {
+		t.Parallel()
+
+		short := "abc"
+		config := &Config{Rooms: []Room{{Slug: "living-room-1", Short: &short}, {}}}
+
+		_, err := konfetty.FromStruct(config).
+			WithDefaults(Room{Slug: "room"}).
+			Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("NonMatching", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{Rooms: []Room{{Slug: "kitchen"}, {Slug: "Living Room"}}}
+
+		_, err := konfetty.FromStruct(config).Build()
+		must.ErrorIs(t, err, konfetty.ErrPatternMismatch)
+		must.ErrorContains(t, err, "Rooms[1].Slug")
+		must.ErrorContains(t, err, `"Living Room"`)
+	})
+
+	t.Run("CommaInPattern", func(t *testing.T) {
+		t.Parallel()
+
+		short := "abcd"
+		config := &Config{Rooms: []Room{{Slug: "kitchen", Short: &short}}}
+
+		_, err := konfetty.FromStruct(config).Build()
+		must.ErrorIs(t, err, konfetty.ErrPatternMismatch)
+		must.ErrorContains(t, err, "Rooms[0].Short")
+	})
+
+	t.Run("MalformedPattern", func(t *testing.T) {
+		t.Parallel()
+
+		type Invalid struct {
+			Name string `konfetty:"pattern=^[a-z+$"`
+		}
+
+		_, err := konfetty.FromStruct(&Invalid{Name: "name"}).Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidTag)
+		must.ErrorContains(t, err, "invalid pattern on field Name")
+	})
+
+	t.Run("NonStringField", func(t *testing.T) {
+		t.Parallel()
+
+		type Invalid struct {
+			Port int `konfetty:"pattern=^[0-9]+$"`
+		}
+
+		_, err := konfetty.FromStruct(&Invalid{Port: 80}).Build()
+		must.ErrorIs(t, err, konfetty.ErrInvalidTag)
+	})
+}
+
+type LightDevice struct {
+	Brightness int
+}
+
+func (l *LightDevice) Validate() error {
+	if l.Brightness > 100 {
+		return fmt.Errorf("brightness %d exceeds 100", l.Brightness)
+	}
+
+	return nil
+}
+
+type ThermostatDevice struct {
+	Temperature float64
+}
+
+func (t ThermostatDevice) Validate() error {
+	if t.Temperature < 5 {
+		return fmt.Errorf("temperature %.1f is below 5", t.Temperature)
+	}
+
+	return nil
+}
+
+func TestValidatable(t *testing.T) {
+	t.Parallel()
+
+	type Room struct {
+		Devices []any
+	}
+
+	type Config struct {
+		Rooms []Room
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{Rooms: []Room{
+			{Devices: []any{&LightDevice{Brightness: 50}, ThermostatDevice{}}},
+			{Devices: []any{LightDevice{Brightness: 80}, &ThermostatDevice{Temperature: 21}}},
+		}}
+
+		_, err := konfetty.FromStruct(config).
+			WithDefaults(ThermostatDevice{Temperature: 20}).
+			Build()
+		must.NoError(t, err)
+	})
+
+	tests := []struct {
+		name     string
+		devices  []any
+		expected string
+	}{
+		{
+			name:     "InvalidPointer",
+			devices:  []any{&LightDevice{Brightness: 150}, ThermostatDevice{Temperature: 20}},
+			expected: "Rooms[1].Devices[0]: brightness 150 exceeds 100",
+		},
+		{
+			name:     "InvalidValueWithPointerReceiver",
+			devices:  []any{LightDevice{Brightness: 120}},
+			expected: "Rooms[1].Devices[0]: brightness 120 exceeds 100",
+		},
+		{
+			name:     "InvalidValue",
+			devices:  []any{&LightDevice{}, ThermostatDevice{Temperature: 2}},
+			expected: "Rooms[1].Devices[1]: temperature 2.0 is below 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Config{Rooms: []Room{{}, {Devices: tt.devices}}}
+
+			_, err := konfetty.FromStruct(config).Build()
+			must.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestWithValidatorForEach(t *testing.T) {
+	t.Parallel()
+
+	type Room struct {
+		Name    string
+		Devices []any
+		Lights  []LightDevice
+		Spare   *LightDevice
+	}
+
+	type Config struct {
+		Rooms  []Room
+		Stored map[string]LightDevice
+	}
+
+	brightness := func(light *LightDevice) error {
+		if light.Brightness < 10 {
+			return fmt.Errorf("brightness %d is below 10", light.Brightness)
+		}
+
+		return nil
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			Rooms:  []Room{{Devices: []any{&LightDevice{}, ThermostatDevice{Temperature: 20}}, Lights: []LightDevice{{}}}},
+			Stored: map[string]LightDevice{"spare": {}},
+		}
+
+		processor := konfetty.FromStruct(config).WithDefaults(LightDevice{Brightness: 50})
+		_, err := konfetty.WithValidatorForEach(processor, brightness).Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			Rooms: []Room{
+				{Devices: []any{&LightDevice{Brightness: 5}, LightDevice{Brightness: 20}}},
+				{Lights: []LightDevice{{Brightness: 30}, {Brightness: 1}}, Spare: &LightDevice{Brightness: 2}},
+			},
+			Stored: map[string]LightDevice{"spare": {Brightness: 3}},
+		}
+
+		_, err := konfetty.WithValidatorForEach(konfetty.FromStruct(config), brightness).Build()
+		must.ErrorContains(t, err, "validate: Rooms[0].Devices[0]: brightness 5 is below 10")
+		must.ErrorContains(t, err, "Rooms[1].Lights[1]: brightness 1 is below 10")
+		must.ErrorContains(t, err, "Rooms[1].Spare: brightness 2 is below 10")
+		must.ErrorContains(t, err, "Stored[spare]: brightness 3 is below 10")
+		must.StrNotContains(t, err.Error(), "brightness 20")
+	})
+}
+
+func TestWithParallelValidation(t *testing.T) {
+	t.Parallel()
+
+	type Device struct {
+		Name string
+		Port int
+	}
+
+	type Config struct {
+		Devices []Device
+		Spares  map[string]Device
+	}
+
+	newConfig := func() *Config {
+		config := &Config{Devices: make([]Device, 2000), Spares: make(map[string]Device)}
+		for i := range config.Devices {
+			config.Devices[i] = Device{Name: fmt.Sprintf("device-%d", i), Port: 8000 + i%100}
+		}
+		for i := range 50 {
+			config.Spares[fmt.Sprintf("spare-%d", i)] = Device{Port: i}
+		}
+
+		return config
+	}
+
+	port := func(device *Device) error {
+		if device.Port%7 == 0 {
+			return fmt.Errorf("port %d is reserved", device.Port)
+		}
+
+		return nil
+	}
+
+	build := func(workers int) error {
+		processor := konfetty.FromStruct(newConfig()).WithParallelValidation(workers)
+		_, err := konfetty.WithValidatorForEach(processor, port).Build()
+
+		return err
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		sequential := build(1)
+		must.ErrorContains(t, sequential, "Devices[1]: port 8001 is reserved")
+		must.ErrorContains(t, sequential, "Spares[spare-7]: port 7 is reserved")
+
+		// The errors are kept in traversal order, regardless of the number of workers and the order they finish in.
+		for _, workers := range []int{2, 8, 64} {
+			must.EqError(t, build(workers), sequential.Error())
+		}
+	})
+
+	t.Run("TraversalOrder", func(t *testing.T) {
+		t.Parallel()
+
+		for _, workers := range []int{1, 8} {
+			config := &Config{Devices: make([]Device, 11)}
+			for i := range config.Devices {
+				config.Devices[i].Port = 1
+			}
+			config.Devices[2].Port = 7
+			config.Devices[10].Port = 14
+
+			processor := konfetty.FromStruct(config).WithParallelValidation(workers)
+			_, err := konfetty.WithValidatorForEach(processor, port).Build()
+			must.EqError(t, err, "validate: Devices[2]: port 7 is reserved\nDevices[10]: port 14 is reserved")
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(newConfig()).WithParallelValidation(8)
+		_, err := konfetty.WithValidatorForEach(processor, func(*Device) error { return nil }).Build()
+		must.NoError(t, err)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(newConfig()).WithParallelValidation(8).WithRecover()
+		_, err := konfetty.WithValidatorForEach(processor, func(device *Device) error {
+			if device.Name == "device-1234" {
+				panic("unreachable device")
+			}
+
+			return nil
+		}).Build()
+		must.ErrorIs(t, err, konfetty.ErrPanic)
+		must.ErrorContains(t, err, "unreachable device")
+	})
+
+	t.Run("InvalidWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		must.ErrorContains(t, build(0), "parallel validation: 0 workers, at least 1 required")
+	})
+}
+
+func BenchmarkWithValidatorForEach(b *testing.B) {
+	type Device struct {
+		Host string
+	}
+
+	type Config struct {
+		Devices []Device
+	}
+
+	config := &Config{Devices: make([]Device, 100)}
+
+	// The validation simulates a network round trip.
+	reachable := func(*Device) error {
+		time.Sleep(10 * time.Microsecond)
+		return nil
+	}
+
+	for _, workers := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("Workers%d", workers), func(b *testing.B) {
+			processor := konfetty.FromStruct(config).WithParallelValidation(workers)
+			processor = konfetty.WithValidatorForEach(processor, reachable)
+
+			b.ResetTimer()
+
+			for range b.N {
+				if _, err := processor.Build(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestWithOnLoaded(t *testing.T) {
+	t.Parallel()
+
+	t.Run("BeforeDefaults", func(t *testing.T) {
+		t.Parallel()
+
+		var loaded []TestConfig
+		result, err := konfetty.FromLoaderFunc(func() (TestConfig, error) {
+			return TestConfig{Name: "Alice"}, nil
+		}).
+			WithDefaults(TestConfig{Name: "Bob", Age: 30}).
+			WithTransformer(func(cfg *TestConfig) {
+				cfg.IsAdmin = true
+			}).
+			WithOnLoaded(func(cfg *TestConfig) {
+				loaded = append(loaded, *cfg)
+			}).
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Alice", Age: 30, IsAdmin: true}, result)
+		must.Eq(t, []TestConfig{{Name: "Alice"}}, loaded)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := konfetty.FromStruct(&TestConfig{}).
+			WithOnLoaded(func(*TestConfig) { panic("audit log unavailable") }).
+			WithRecover().
+			Build()
+		must.ErrorIs(t, err, konfetty.ErrPanic)
+		must.ErrorContains(t, err, "on loaded hook")
+	})
+}
+
+func TestWithPostValidateHook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		result, err := konfetty.FromStruct(&TestConfig{Name: "Alice"}).
+			WithDefaults(TestConfig{Age: 30}).
+			WithValidator(func(*TestConfig) error {
+				calls = append(calls, "validator")
+				return nil
+			}).
+			WithPostValidateHook(func(cfg *TestConfig) {
+				calls = append(calls, fmt.Sprintf("hook %s %d", cfg.Name, cfg.Age))
+			}).
+			WithPostValidateHook(func(*TestConfig) {
+				calls = append(calls, "second hook")
+			}).
+			Build()
+
+		must.NoError(t, err)
+		must.Eq(t, &TestConfig{Name: "Alice", Age: 30}, result)
+		must.Eq(t, []string{"validator", "hook Alice 30", "second hook"}, calls)
+	})
+
+	t.Run("ValidationFails", func(t *testing.T) {
+		t.Parallel()
+
+		called := false
+		_, err := konfetty.FromStruct(&TestConfig{}).
+			WithValidator(func(*TestConfig) error { return errors.New("invalid") }).
+			WithPostValidateHook(func(*TestConfig) { called = true }).
+			Build()
+
+		must.ErrorContains(t, err, "invalid")
+		must.False(t, called)
+	})
+}
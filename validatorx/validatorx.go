@@ -0,0 +1,67 @@
+// Package validatorx checks the `validate` struct tags of go-playground/validator during konfetty's validation stage.
+// It lives in its own module, so that the core konfetty module stays free of dependencies.
+package validatorx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/nikoksr/konfetty"
+)
+
+// WithValidator adds a validator to the processor that checks the data-structure against its `validate` struct tags,
+// e.g. `validate:"required,min=1"`, using v. If v is nil, a validator with required checks on structs enabled is used.
+// The errors of all invalid fields are returned together, each prefixed with the field's path, e.g.
+// `Database.Port: fails the "min=1" rule`.
+//
+//	processor := konfetty.FromStruct(cfg)
+//	validatorx.WithValidator(processor, nil)
+func WithValidator[T any](p *konfetty.Processor[T], v *validator.Validate) *konfetty.Processor[T] {
+	if v == nil {
+		v = validator.New(validator.WithRequiredStructEnabled())
+	}
+
+	return p.WithValidators(func(cfg *T) error {
+		return validate(v, cfg)
+	})
+}
+
+// validate validates cfg and turns the validation errors into path-qualified errors.
+func validate(v *validator.Validate, cfg any) error {
+	err := v.Struct(cfg)
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	errs := make([]error, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		errs = append(errs, fmt.Errorf("%s: fails the %q rule", fieldPath(fieldErr), rule(fieldErr)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// fieldPath returns the path of the field relative to the root struct, e.g. `Database.Port`.
+func fieldPath(fieldErr validator.FieldError) string {
+	// The namespace starts with the name of the root struct, which isn't part of konfetty's paths.
+	_, path, found := strings.Cut(fieldErr.StructNamespace(), ".")
+	if !found {
+		return fieldErr.StructNamespace()
+	}
+
+	return path
+}
+
+// rule returns the failed rule along with its parameter, e.g. `min=1`.
+func rule(fieldErr validator.FieldError) string {
+	if fieldErr.Param() == "" {
+		return fieldErr.Tag()
+	}
+
+	return fieldErr.Tag() + "=" + fieldErr.Param()
+}
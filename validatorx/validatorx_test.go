@@ -0,0 +1,68 @@
+package validatorx_test
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shoenig/test/must"
+
+	"github.com/nikoksr/konfetty"
+	"github.com/nikoksr/konfetty/validatorx"
+)
+
+type DatabaseConfig struct {
+	Host string `validate:"required,hostname"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+type AppConfig struct {
+	Database DatabaseConfig
+	Replicas []DatabaseConfig `validate:"dive"`
+	LogLevel string           `validate:"oneof=debug info warn error"`
+}
+
+func TestWithValidator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(&AppConfig{Database: DatabaseConfig{Host: "db.internal"}}).
+			WithDefaults(DatabaseConfig{Port: 5432}, AppConfig{LogLevel: "info"})
+		validatorx.WithValidator(processor, nil)
+
+		result, err := processor.Build()
+		must.NoError(t, err)
+		must.Eq(t, 5432, result.Database.Port)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		processor := konfetty.FromStruct(&AppConfig{
+			Database: DatabaseConfig{Port: 70000},
+			Replicas: []DatabaseConfig{{Host: "replica.internal", Port: 5432}, {Host: "-invalid-", Port: 5432}},
+			LogLevel: "verbose",
+		})
+		validatorx.WithValidator(processor, nil)
+
+		_, err := processor.Build()
+		must.ErrorContains(t, err, `Database.Host: fails the "required" rule`)
+		must.ErrorContains(t, err, `Database.Port: fails the "max=65535" rule`)
+		must.ErrorContains(t, err, `Replicas[1].Host: fails the "hostname" rule`)
+		must.ErrorContains(t, err, `LogLevel: fails the "oneof=debug info warn error" rule`)
+	})
+
+	t.Run("CustomValidator", func(t *testing.T) {
+		t.Parallel()
+
+		v := validator.New()
+		must.NoError(t, v.RegisterValidation("hostname", func(validator.FieldLevel) bool { return true }))
+
+		processor := konfetty.FromStruct(&AppConfig{Database: DatabaseConfig{Host: "-any-", Port: 1}, LogLevel: "info"})
+		validatorx.WithValidator(processor, v)
+
+		_, err := processor.Build()
+		must.NoError(t, err)
+	})
+}
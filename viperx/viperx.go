@@ -0,0 +1,51 @@
+// Package viperx provides a konfetty provider that loads the data-structure from a viper instance. It lives in its own
+// module, so that the core konfetty module stays free of dependencies.
+package viperx
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/nikoksr/konfetty"
+)
+
+// Provider loads a T by unmarshalling a viper instance. It implements konfetty.Provider.
+type Provider[T any] struct {
+	v      *viper.Viper
+	opts   []viper.DecoderConfigOption
+	strict bool
+}
+
+var _ konfetty.Provider[struct{}] = (*Provider[struct{}])(nil)
+
+// New returns a Provider that unmarshals all of v, passing the decoder options on to viper. The unmarshalling happens
+// on every build, so changes read into v in the meantime are picked up.
+//
+//	v := viper.New()
+//	v.SetConfigFile("config.yaml")
+//	if err := v.ReadInConfig(); err != nil { ... }
+//	processor := konfetty.FromProvider(viperx.New[AppConfig](v))
+func New[T any](v *viper.Viper, opts ...viper.DecoderConfigOption) *Provider[T] {
+	return &Provider[T]{v: v, opts: opts}
+}
+
+// WithStrict makes Load fail if the viper instance holds keys that don't map to any field of T, which usually hints
+// at a typo in the config file. The error lists the unknown keys.
+func (p *Provider[T]) WithStrict() *Provider[T] {
+	p.strict = true
+	return p
+}
+
+// Load unmarshals the config from the viper instance.
+func (p *Provider[T]) Load() (T, error) {
+	unmarshal := p.v.Unmarshal
+	if p.strict {
+		unmarshal = p.v.UnmarshalExact
+	}
+
+	var cfg T
+	if err := unmarshal(&cfg, p.opts...); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,67 @@
+package viperx_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test/must"
+	"github.com/spf13/viper"
+
+	"github.com/nikoksr/konfetty"
+	"github.com/nikoksr/konfetty/viperx"
+)
+
+type DatabaseConfig struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type AppConfig struct {
+	Database DatabaseConfig `mapstructure:"database"`
+	LogLevel string         `mapstructure:"log_level"`
+}
+
+func newViper(t *testing.T, config string) *viper.Viper {
+	t.Helper()
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	must.NoError(t, v.ReadConfig(strings.NewReader(config)))
+
+	return v
+}
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+
+	v := newViper(t, "database:\n  port: 6543\nlog_level: debug\n")
+
+	result, err := konfetty.FromProvider(viperx.New[AppConfig](v)).
+		WithDefaults(DatabaseConfig{Host: "localhost", Port: 5432}).
+		Build()
+	must.NoError(t, err)
+	must.Eq(t, &AppConfig{Database: DatabaseConfig{Host: "localhost", Port: 6543}, LogLevel: "debug"}, result)
+}
+
+func TestProviderError(t *testing.T) {
+	t.Parallel()
+
+	v := newViper(t, "database:\n  port: not a number\n")
+
+	_, err := konfetty.FromProvider(viperx.New[AppConfig](v)).Build()
+	must.ErrorContains(t, err, "load: from provider")
+	must.ErrorContains(t, err, "port")
+}
+
+func TestProviderStrict(t *testing.T) {
+	t.Parallel()
+
+	v := newViper(t, "database:\n  port: 6543\n  hots: db.internal\n")
+
+	result, err := viperx.New[AppConfig](v).Load()
+	must.NoError(t, err)
+	must.Eq(t, 6543, result.Database.Port)
+
+	_, err = konfetty.FromProvider(viperx.New[AppConfig](v).WithStrict()).Build()
+	must.ErrorContains(t, err, "hots")
+}